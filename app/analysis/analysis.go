@@ -0,0 +1,170 @@
+package analysis
+
+import (
+	"encoding/json"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"io/ioutil"
+	"log"
+	"math"
+)
+
+type HitEvent struct {
+	Object int64   `json:"object"`
+	Time   int64   `json:"time"`
+	Result int64   `json:"result"`
+	Combo  bool    `json:"comboBreak"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// Totals stored in replay's header
+type Expected struct {
+	Score    int64 `json:"score"`
+	MaxCombo int64 `json:"maxCombo"`
+	Count300 int64 `json:"count300"`
+	Count100 int64 `json:"count100"`
+	Count50  int64 `json:"count50"`
+	Misses   int64 `json:"misses"`
+}
+
+type PlayerReport struct {
+	Name     string     `json:"name"`
+	Mods     string     `json:"mods"`
+	Score    int64      `json:"score"`
+	Accuracy float64    `json:"accuracy"`
+	MaxCombo int64      `json:"maxCombo"`
+	Count300 int64      `json:"count300"`
+	Count100 int64      `json:"count100"`
+	Count50  int64      `json:"count50"`
+	Misses   int64      `json:"misses"`
+	Geki     int64      `json:"geki"`
+	Katu     int64      `json:"katu"`
+	Grade    string     `json:"grade"`
+	PP       float64    `json:"pp"`
+	Hits     []HitEvent `json:"hits"`
+	Expected *Expected  `json:"expected,omitempty"`
+}
+
+type Report struct {
+	BeatmapMD5 string          `json:"beatmapMD5"`
+	Artist     string          `json:"artist"`
+	Title      string          `json:"title"`
+	Difficulty string          `json:"difficulty"`
+	Creator    string          `json:"creator"`
+	Players    []*PlayerReport `json:"players"`
+
+	Timings *TimingReport `json:"-"`
+}
+
+// AnalyzeReplay runs replays selected by settings.REPLAY (or knockout replays) through the osu! ruleset without rendering anything.
+// settings.HEADLESS has to be set and beatmap objects have to be parsed beforehand.
+func AnalyzeReplay(beatMap *beatmap.BeatMap) *Report {
+	controller := dance.NewReplayController().(*dance.ReplayController)
+	controller.SetBeatMap(beatMap)
+	controller.InitCursors()
+
+	cursors := controller.GetCursors()
+	replays := controller.GetReplays()
+
+	players := make(map[*graphics.Cursor]*PlayerReport)
+
+	timings := NewTimingCollector(beatMap)
+
+	report := &Report{
+		BeatmapMD5: beatMap.MD5,
+		Artist:     beatMap.Artist,
+		Title:      beatMap.Name,
+		Difficulty: beatMap.Difficulty,
+		Creator:    beatMap.Creator,
+	}
+
+	for i, cursor := range cursors {
+		player := &PlayerReport{
+			Name: controller.GetPlayerName(i),
+			Mods: replays[i].ModsV.String(),
+			Hits: make([]HitEvent, 0),
+		}
+
+		if header := controller.GetReplayHeader(i); header != nil {
+			player.Expected = &Expected{
+				Score:    int64(header.Score),
+				MaxCombo: int64(header.MaxCombo),
+				Count300: int64(header.Count300),
+				Count100: int64(header.Count100),
+				Count50:  int64(header.Count50),
+				Misses:   int64(header.CountMiss),
+			}
+		}
+
+		players[cursor] = player
+		timings.AddPlayer(cursor, player.Name, replays[i].ModsV)
+		report.Players = append(report.Players, player)
+	}
+
+	ruleset := controller.GetRuleset()
+
+	ruleset.SetListener(func(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, pp float64, score int64) {
+		timings.Add(cursor, time, number, result, comboResult)
+
+		if result&osu.BaseHitsM == 0 {
+			return
+		}
+
+		players[cursor].Hits = append(players[cursor].Hits, HitEvent{
+			Object: number,
+			Time:   time,
+			Result: result.ScoreValue(),
+			Combo:  comboResult == osu.ComboResults.Reset,
+			X:      position.X,
+			Y:      position.Y,
+		})
+	})
+
+	objects := beatMap.HitObjects
+
+	startTime := math.Min(-200, objects[0].GetStartTime()-beatMap.Diff.Preempt)
+	endTime := objects[len(objects)-1].GetEndTime() + float64(beatMap.Diff.Hit50) + difficulty.PostEmpt
+
+	log.Println("Analyzing replays...")
+
+	for time := startTime; time <= endTime; time++ {
+		controller.Update(time, 1)
+	}
+
+	for cursor, player := range players {
+		accuracy, combo, score, grade := ruleset.GetResults(cursor)
+		n300, n100, n50, nMiss, nGeki, nKatu := ruleset.GetHits(cursor)
+
+		player.Score = score
+		player.Accuracy = accuracy
+		player.MaxCombo = combo
+		player.Count300 = n300
+		player.Count100 = n100
+		player.Count50 = n50
+		player.Misses = nMiss
+		player.Geki = nGeki
+		player.Katu = nKatu
+		player.Grade = osu.GradesText[grade]
+		player.PP = ruleset.GetPP(cursor)
+	}
+
+	report.Timings = timings.Finish()
+
+	log.Println("Analysis finished!")
+
+	return report
+}
+
+func (report *Report) Save(path string) error {
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
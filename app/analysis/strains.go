@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/oppai"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+type StrainPoint struct {
+	Object      int     `json:"object"`
+	Time        float64 `json:"time"`
+	AimStrain   float64 `json:"aimStrain"`
+	SpeedStrain float64 `json:"speedStrain"`
+	TotalStrain float64 `json:"totalStrain"`
+	AimStars    float64 `json:"aimStars"`
+	SpeedStars  float64 `json:"speedStars"`
+	Stars       float64 `json:"stars"`
+}
+
+type StrainReport struct {
+	BeatmapMD5 string        `json:"beatmapMD5"`
+	Mods       string        `json:"mods"`
+	Points     []StrainPoint `json:"points"`
+}
+
+// CalculateStrains builds per object strain report, beatmap objects have to be parsed beforehand
+func CalculateStrains(beatMap *beatmap.BeatMap, mods difficulty.Modifier) *StrainReport {
+	diff := beatMap.Diff.Clone()
+	diff.SetMods(mods)
+
+	report := &StrainReport{
+		BeatmapMD5: beatMap.MD5,
+		Mods:       mods.String(),
+		Points:     make([]StrainPoint, 0, len(beatMap.HitObjects)),
+	}
+
+	for _, s := range oppai.CalculateStrains(beatMap.HitObjects, diff, false) {
+		report.Points = append(report.Points, StrainPoint{
+			Object:      s.Object,
+			Time:        s.Time,
+			AimStrain:   s.Aim,
+			SpeedStrain: s.Speed,
+			TotalStrain: s.Total,
+			AimStars:    s.Stars.Aim,
+			SpeedStars:  s.Stars.Speed,
+			Stars:       s.Stars.Total,
+		})
+	}
+
+	return report
+}
+
+// Save writes the report as CSV if path ends with .csv, JSON otherwise
+func (report *StrainReport) Save(path string) error {
+	if strings.ToLower(filepath.Ext(path)) != ".csv" {
+		data, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+	records := [][]string{{"object", "time", "aimStrain", "speedStrain", "totalStrain", "aimStars", "speedStars", "stars"}}
+
+	for _, p := range report.Points {
+		records = append(records, []string{
+			fmt.Sprint(p.Object),
+			formatFloat(p.Time),
+			formatFloat(p.AimStrain),
+			formatFloat(p.SpeedStrain),
+			formatFloat(p.TotalStrain),
+			formatFloat(p.AimStars),
+			formatFloat(p.SpeedStars),
+			formatFloat(p.Stars),
+		})
+	}
+
+	return writeCSV(path, records)
+}
@@ -0,0 +1,295 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	TimingHit         = "hit"
+	TimingSliderBreak = "sliderbreak"
+	TimingMiss        = "miss"
+)
+
+type TimingEvent struct {
+	Object int64   `json:"object"`
+	Time   int64   `json:"time"`
+	Type   string  `json:"type"`
+	Error  float64 `json:"error"`
+}
+
+type ErrorStats struct {
+	Count        int     `json:"count"`
+	UnstableRate float64 `json:"unstableRate"`
+	// UnstableRate divided by speed multiplier, the value shown by HitErrorMeter
+	ConvertedUnstableRate float64 `json:"convertedUnstableRate"`
+	Mean                  float64 `json:"mean"`
+	EarlyCount            int     `json:"earlyCount"`
+	EarlyMean             float64 `json:"earlyMean"`
+	LateCount             int     `json:"lateCount"`
+	LateMean              float64 `json:"lateMean"`
+}
+
+// BPMSection holds hit errors of objects placed between two BPM changes. BPM includes speed mods.
+type BPMSection struct {
+	StartTime float64 `json:"startTime"`
+	BPM       float64 `json:"bpm"`
+	ErrorStats
+}
+
+type PlayerTimings struct {
+	Name         string        `json:"name"`
+	Mods         string        `json:"mods"`
+	SliderBreaks int           `json:"sliderBreaks"`
+	Misses       int           `json:"misses"`
+	Summary      ErrorStats    `json:"summary"`
+	Sections     []*BPMSection `json:"sections"`
+	Events       []TimingEvent `json:"events"`
+
+	speed float64
+}
+
+type TimingReport struct {
+	BeatmapMD5 string           `json:"beatmapMD5"`
+	Players    []*PlayerTimings `json:"players"`
+}
+
+// TimingCollector gathers signed hit errors, slider breaks and misses from OsuRuleSet's hit listener
+type TimingCollector struct {
+	beatMap *beatmap.BeatMap
+	players map[*graphics.Cursor]*PlayerTimings
+	order   []*PlayerTimings
+}
+
+func NewTimingCollector(beatMap *beatmap.BeatMap) *TimingCollector {
+	return &TimingCollector{
+		beatMap: beatMap,
+		players: make(map[*graphics.Cursor]*PlayerTimings),
+	}
+}
+
+func (collector *TimingCollector) AddPlayer(cursor *graphics.Cursor, name string, mods difficulty.Modifier) {
+	diff := difficulty.NewDifficulty(0, 0, 0, 0)
+	diff.SetMods(mods)
+
+	player := &PlayerTimings{
+		Name:   name,
+		Mods:   mods.String(),
+		Events: make([]TimingEvent, 0),
+		speed:  diff.Speed,
+	}
+
+	collector.players[cursor] = player
+	collector.order = append(collector.order, player)
+}
+
+// Add has to be called from ruleset's hit listener. Hit error is measured the same way HitErrorMeter does it.
+func (collector *TimingCollector) Add(cursor *graphics.Cursor, time int64, number int64, result osu.HitResult, comboResult osu.ComboResult) {
+	player, ok := collector.players[cursor]
+	if !ok {
+		return
+	}
+
+	object := collector.beatMap.HitObjects[number]
+
+	_, isCircle := object.(*objects.Circle)
+	_, isSlider := object.(*objects.Slider)
+
+	event := TimingEvent{
+		Object: number,
+		Time:   time,
+	}
+
+	switch {
+	case (isCircle && result&osu.BaseHits > 0) || (isSlider && result == osu.SliderStart):
+		event.Type = TimingHit
+		event.Error = float64(time) - object.GetStartTime()
+	case result == osu.SliderMiss && comboResult == osu.ComboResults.Reset:
+		event.Type = TimingSliderBreak
+		player.SliderBreaks++
+	case result == osu.Miss:
+		event.Type = TimingMiss
+		player.Misses++
+	default:
+		return
+	}
+
+	player.Events = append(player.Events, event)
+}
+
+// Finish calculates summary and per BPM section statistics
+func (collector *TimingCollector) Finish() *TimingReport {
+	report := &TimingReport{
+		BeatmapMD5: collector.beatMap.MD5,
+		Players:    collector.order,
+	}
+
+	points := collector.beatMap.Timings.Points
+
+	for _, player := range collector.order {
+		player.Sections = make([]*BPMSection, 0)
+
+		var all []float64
+		var sectionErrors [][]float64
+
+		for _, point := range points {
+			if len(player.Sections) > 0 && player.Sections[len(player.Sections)-1].BPM == 60000/point.BaseBpm*player.speed {
+				continue
+			}
+
+			player.Sections = append(player.Sections, &BPMSection{
+				StartTime: point.Time,
+				BPM:       60000 / point.BaseBpm * player.speed,
+			})
+
+			sectionErrors = append(sectionErrors, nil)
+		}
+
+		for _, event := range player.Events {
+			if event.Type != TimingHit {
+				continue
+			}
+
+			all = append(all, event.Error)
+
+			startTime := collector.beatMap.HitObjects[event.Object].GetStartTime()
+
+			index := 0
+			for i, section := range player.Sections {
+				if section.StartTime <= startTime {
+					index = i
+				}
+			}
+
+			if len(sectionErrors) > 0 {
+				sectionErrors[index] = append(sectionErrors[index], event.Error)
+			}
+		}
+
+		player.Summary = calculateErrorStats(all, player.speed)
+
+		for i, section := range player.Sections {
+			section.ErrorStats = calculateErrorStats(sectionErrors[i], player.speed)
+		}
+	}
+
+	return report
+}
+
+func calculateErrorStats(errors []float64, speed float64) (stats ErrorStats) {
+	stats.Count = len(errors)
+
+	if stats.Count == 0 {
+		return
+	}
+
+	sumEarly, sumLate := 0.0, 0.0
+
+	for _, e := range errors {
+		if e >= 0 {
+			sumLate += e
+			stats.LateCount++
+		} else {
+			sumEarly += e
+			stats.EarlyCount++
+		}
+	}
+
+	stats.Mean = (sumEarly + sumLate) / float64(stats.Count)
+	stats.EarlyMean = sumEarly / math.Max(float64(stats.EarlyCount), 1)
+	stats.LateMean = sumLate / math.Max(float64(stats.LateCount), 1)
+
+	variance := 0.0
+	for _, e := range errors {
+		variance += math.Pow(e-stats.Mean, 2)
+	}
+
+	variance /= float64(stats.Count)
+
+	stats.UnstableRate = math.Sqrt(variance) * 10
+	stats.ConvertedUnstableRate = stats.UnstableRate / speed
+
+	return
+}
+
+// Save writes the report as JSON or, if path ends with .csv, as two CSV files: events and <name>_summary.csv
+func (report *TimingReport) Save(path string) error {
+	if strings.ToLower(filepath.Ext(path)) != ".csv" {
+		data, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+	events := [][]string{{"player", "object", "time", "type", "error"}}
+
+	for _, player := range report.Players {
+		for _, e := range player.Events {
+			events = append(events, []string{player.Name, fmt.Sprint(e.Object), fmt.Sprint(e.Time), e.Type, formatFloat(e.Error)})
+		}
+	}
+
+	if err := writeCSV(path, events); err != nil {
+		return err
+	}
+
+	summary := [][]string{{"player", "mods", "section", "startTime", "bpm", "count", "ur", "convertedUr", "mean", "earlyCount", "earlyMean", "lateCount", "lateMean", "sliderBreaks", "misses"}}
+
+	statsRow := func(stats ErrorStats) []string {
+		return []string{
+			fmt.Sprint(stats.Count),
+			formatFloat(stats.UnstableRate),
+			formatFloat(stats.ConvertedUnstableRate),
+			formatFloat(stats.Mean),
+			fmt.Sprint(stats.EarlyCount),
+			formatFloat(stats.EarlyMean),
+			fmt.Sprint(stats.LateCount),
+			formatFloat(stats.LateMean),
+		}
+	}
+
+	for _, player := range report.Players {
+		row := append([]string{player.Name, player.Mods, "all", "", ""}, statsRow(player.Summary)...)
+		summary = append(summary, append(row, fmt.Sprint(player.SliderBreaks), fmt.Sprint(player.Misses)))
+
+		for i, section := range player.Sections {
+			row = append([]string{player.Name, player.Mods, fmt.Sprint(i), formatFloat(section.StartTime), formatFloat(section.BPM)}, statsRow(section.ErrorStats)...)
+			summary = append(summary, append(row, "", ""))
+		}
+	}
+
+	return writeCSV(strings.TrimSuffix(path, filepath.Ext(path))+"_summary.csv", summary)
+}
+
+func writeCSV(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+
+	if err = writer.WriteAll(records); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
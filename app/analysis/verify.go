@@ -0,0 +1,192 @@
+package analysis
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Mismatch struct {
+	Field    string
+	Expected int64
+	Actual   int64
+}
+
+// Verify compares simulated totals with the ones stored in replay's header.
+// It also returns the first object where simulated counts provably exceed the replay's, -1 if they never do.
+// Judgements may have diverged at an earlier object already, it's only the point where it can be proven.
+func (player *PlayerReport) Verify() (mismatches []Mismatch, exceeded int64) {
+	exceeded = -1
+
+	if player.Expected == nil {
+		return
+	}
+
+	check := func(field string, expected, actual int64) {
+		if expected != actual {
+			mismatches = append(mismatches, Mismatch{field, expected, actual})
+		}
+	}
+
+	check("score", player.Expected.Score, player.Score)
+	check("max combo", player.Expected.MaxCombo, player.MaxCombo)
+	check("300", player.Expected.Count300, player.Count300)
+	check("100", player.Expected.Count100, player.Count100)
+	check("50", player.Expected.Count50, player.Count50)
+	check("miss", player.Expected.Misses, player.Misses)
+
+	if len(mismatches) == 0 {
+		return
+	}
+
+	limits := map[int64]int64{
+		300: player.Expected.Count300,
+		100: player.Expected.Count100,
+		50:  player.Expected.Count50,
+		0:   player.Expected.Misses,
+	}
+
+	counts := make(map[int64]int64)
+
+	for _, hit := range player.Hits {
+		counts[hit.Result]++
+
+		if counts[hit.Result] > limits[hit.Result] {
+			exceeded = hit.Object
+			break
+		}
+	}
+
+	return
+}
+
+// VerifyReplays runs every .osr file found in path (file or directory) through the ruleset and compares results with replay headers.
+// Returns false if any replay failed to load or didn't match.
+func VerifyReplays(path string, beatmaps []*beatmap.BeatMap) bool {
+	var files []string
+
+	if stat, err := os.Stat(path); err != nil {
+		log.Println("Failed to open:", err)
+		return false
+	} else if stat.IsDir() {
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			log.Println("Failed to read directory:", err)
+			return false
+		}
+
+		for _, info := range infos {
+			if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".osr") {
+				files = append(files, filepath.Join(path, info.Name()))
+			}
+		}
+	} else {
+		files = append(files, path)
+	}
+
+	failed := 0
+
+	for _, file := range files {
+		if !verifyReplay(file, beatmaps) {
+			failed++
+		}
+	}
+
+	log.Println(fmt.Sprintf("Verified %d replays, %d passed, %d failed", len(files), len(files)-failed, failed))
+
+	return failed == 0
+}
+
+func verifyReplay(path string, beatmaps []*beatmap.BeatMap) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Println("FAIL:", path, "- panic:", err)
+
+			for _, s := range utils.GetPanicStackTrace() {
+				log.Println(s)
+			}
+
+			ok = false
+		}
+	}()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Println("FAIL:", path, "-", err)
+		return false
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		log.Println("FAIL:", path, "-", err)
+		return false
+	}
+
+	if replay.PlayMode != 0 {
+		log.Println("FAIL:", path, "- modes other than osu!standard are not supported")
+		return false
+	}
+
+	var beatMap *beatmap.BeatMap
+
+	for _, b := range beatmaps {
+		if strings.EqualFold(b.MD5, replay.BeatmapMD5) {
+			beatMap = b
+			break
+		}
+	}
+
+	if beatMap == nil {
+		log.Println("FAIL:", path, "- beatmap not found:", replay.BeatmapMD5)
+		return false
+	}
+
+	settings.REPLAY = path
+
+	beatMap.HitObjects = nil
+	beatMap.Diff.SetMods(difficulty.Modifier(replay.Mods))
+	beatmap.ParseTimingPointsAndPauses(beatMap)
+	beatmap.ParseObjects(beatMap)
+
+	report := AnalyzeReplay(beatMap)
+
+	if len(report.Players) == 0 {
+		log.Println("FAIL:", path, "- replay has no input data")
+		return false
+	}
+
+	ok = true
+
+	for _, player := range report.Players {
+		mismatches, exceeded := player.Verify()
+
+		if len(mismatches) == 0 {
+			log.Println("OK:", path, "-", player.Name)
+			continue
+		}
+
+		ok = false
+
+		log.Println("FAIL:", path, "-", player.Name)
+
+		for _, m := range mismatches {
+			log.Println(fmt.Sprintf("\t%s: expected %d, got %d", m.Field, m.Expected, m.Actual))
+		}
+
+		if exceeded >= 0 {
+			log.Println(fmt.Sprintf("\tfirst object where counts provably exceed the replay: %d (%.0fms)", exceeded, beatMap.HitObjects[exceeded].GetStartTime()))
+		} else {
+			log.Println("\tcounts never exceed the replay, couldn't determine where judgements diverged")
+		}
+	}
+
+	return
+}
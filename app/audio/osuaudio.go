@@ -203,6 +203,8 @@ func LoadBeatmapSamples(dir string) {
 		return []string{name}
 	}
 
+	MapSamples = [3][7]map[int]*bass.Sample{}
+
 	fullPath := settings.General.OsuSongsDir + string(os.PathSeparator) + dir
 
 	filepath.Walk(fullPath, func(path string, info os.FileInfo, err error) error {
@@ -70,6 +70,21 @@ func NewBeatMap() *BeatMap {
 	return beatMap
 }
 
+// Copy returns beatmap's metadata without parsed timing points and objects, so it can be loaded again with other mods
+func (beatMap *BeatMap) Copy() *BeatMap {
+	bMap := *beatMap
+
+	bMap.Diff = difficulty.NewDifficulty(beatMap.Diff.GetHPDrain(), beatMap.Diff.GetCS(), beatMap.Diff.GetOD(), beatMap.Diff.GetAR())
+	bMap.Timings = objects.NewTimings()
+	bMap.HitObjects = nil
+	bMap.Pauses = nil
+	bMap.Queue = nil
+	bMap.MinBPM = math.Inf(0)
+	bMap.MaxBPM = 0
+
+	return &bMap
+}
+
 func (b *BeatMap) Reset() {
 	b.Queue = make([]objects.IHitObject, len(b.HitObjects))
 	copy(b.Queue, b.HitObjects)
@@ -79,6 +94,21 @@ func (b *BeatMap) Reset() {
 	}
 }
 
+// Rewind queues again objects starting at or after the given time, objects before it won't be updated anymore
+func (b *BeatMap) Rewind(time float64) {
+	b.Queue = make([]objects.IHitObject, 0, len(b.HitObjects))
+	b.Timings.Reset()
+
+	for _, o := range b.HitObjects {
+		if o.GetStartTime() < time {
+			continue
+		}
+
+		o.SetDifficulty(b.Diff)
+		b.Queue = append(b.Queue, o)
+	}
+}
+
 func (b *BeatMap) Update(time float64) {
 	b.Timings.Update(time)
 
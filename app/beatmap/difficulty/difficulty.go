@@ -23,9 +23,14 @@ type Difficulty struct {
 	SpinnerRatio        float64
 	Speed               float64
 
-	ARReal      float64
-	ODReal      float64
-	CustomSpeed float64
+	ARReal float64
+	ODReal float64
+
+	params ModParams
+
+	rampStart, rampEnd float64
+
+	hitWindows [3]float64
 }
 
 func NewDifficulty(hpDrain, cs, od, ar float64) *Difficulty {
@@ -34,13 +39,13 @@ func NewDifficulty(hpDrain, cs, od, ar float64) *Difficulty {
 	diff.cs = cs
 	diff.od = od
 	diff.ar = ar
-	diff.CustomSpeed = 1
+	diff.params = NewModParams()
 	diff.calculate()
 	return diff
 }
 
 func (diff *Difficulty) calculate() {
-	hpDrain, cs, od, ar := diff.hpDrain, diff.cs, diff.od, diff.ar
+	hpDrain, cs, od, ar := diff.GetHPDrain(), diff.GetCS(), diff.GetOD(), diff.GetAR()
 
 	if diff.Mods&HardRock > 0 {
 		ar = math.Min(ar*1.4, 10)
@@ -63,6 +68,7 @@ func (diff *Difficulty) calculate() {
 	diff.Hit50 = int64(DifficultyRate(od, 200, 150, 100))
 	diff.Hit100 = int64(DifficultyRate(od, 140, 100, 60))
 	diff.Hit300 = int64(DifficultyRate(od, 80, 50, 20))
+	diff.hitWindows = [3]float64{DifficultyRate(od, 80, 50, 20), DifficultyRate(od, 140, 100, 60), DifficultyRate(od, 200, 150, 100)}
 	diff.SpinnerRatio = DifficultyRate(od, 3, 5, 7.5)
 	diff.Speed = 1.0 / diff.GetModifiedTime(1)
 
@@ -70,6 +76,11 @@ func (diff *Difficulty) calculate() {
 	diff.ODReal = DiffFromRate(diff.GetModifiedTime(float64(diff.Hit300)), 80, 50, 20)
 }
 
+// GetHitWindows returns 300, 100 and 50 hit windows before truncating them to whole milliseconds
+func (diff *Difficulty) GetHitWindows() (hit300, hit100, hit50 float64) {
+	return diff.hitWindows[0], diff.hitWindows[1], diff.hitWindows[2]
+}
+
 func (diff *Difficulty) SetMods(mods Modifier) {
 	diff.Mods = mods
 	diff.calculate()
@@ -79,17 +90,23 @@ func (diff *Difficulty) CheckModActive(mods Modifier) bool {
 	return diff.Mods&mods > 0
 }
 
+// GetModifiedTime converts beatmap duration to real one, Wind Up/Wind Down are taken into account with their initial rate
 func (diff *Difficulty) GetModifiedTime(time float64) float64 {
-	if diff.Mods&DoubleTime > 0 {
-		return time / (1.5 * diff.CustomSpeed)
-	} else if diff.Mods&HalfTime > 0 {
-		return time / (0.75 * diff.CustomSpeed)
-	} else {
-		return time / diff.CustomSpeed
-	}
+	initial, _ := diff.getRampRates()
+	return time / (diff.getBaseSpeed() * initial)
+}
+
+// Clone returns a copy of the difficulty with the same mods and their parameters
+func (diff *Difficulty) Clone() *Difficulty {
+	clone := *diff
+	return &clone
 }
 
 func (diff *Difficulty) GetHPDrain() float64 {
+	if !math.IsNaN(diff.params.HP) {
+		return diff.params.HP
+	}
+
 	return diff.hpDrain
 }
 
@@ -99,6 +116,10 @@ func (diff *Difficulty) SetHPDrain(hpDrain float64) {
 }
 
 func (diff *Difficulty) GetCS() float64 {
+	if !math.IsNaN(diff.params.CS) {
+		return diff.params.CS
+	}
+
 	return diff.cs
 }
 
@@ -108,6 +129,10 @@ func (diff *Difficulty) SetCS(cs float64) {
 }
 
 func (diff *Difficulty) GetOD() float64 {
+	if !math.IsNaN(diff.params.OD) {
+		return diff.params.OD
+	}
+
 	return diff.od
 }
 
@@ -117,6 +142,10 @@ func (diff *Difficulty) SetOD(od float64) {
 }
 
 func (diff *Difficulty) GetAR() float64 {
+	if !math.IsNaN(diff.params.AR) {
+		return diff.params.AR
+	}
+
 	return diff.ar
 }
 
@@ -125,11 +154,6 @@ func (diff *Difficulty) SetAR(ar float64) {
 	diff.calculate()
 }
 
-func (diff *Difficulty) SetCustomSpeed(speed float64) {
-	diff.CustomSpeed = speed
-	diff.calculate()
-}
-
 func DifficultyRate(diff, min, mid, max float64) float64 {
 	diff = float64(float32(diff))
 
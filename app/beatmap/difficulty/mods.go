@@ -36,7 +36,16 @@ const (
 	ScoreV2
 	LastMod
 	Daycore
-	DifficultyAdjustMask = HardRock | Easy | DoubleTime | Nightcore | HalfTime | Daycore | TouchDevice
+	// Mods below don't exist in osu!stable, their settings are stored in ModParams
+	DifficultyAdjust
+	WindUp
+	WindDown
+	Mirror
+	Grow
+	Deflate
+	Transform
+	Wiggle
+	DifficultyAdjustMask = HardRock | Easy | DoubleTime | Nightcore | HalfTime | Daycore | TouchDevice | DifficultyAdjust | WindUp | WindDown
 )
 
 var modsString = [...]string{
@@ -72,6 +81,14 @@ var modsString = [...]string{
 	"V2",
 	"LM",
 	"DC",
+	"DA",
+	"WU",
+	"WD",
+	"MR",
+	"GR",
+	"DF",
+	"TR",
+	"WG",
 }
 
 var modsStringFull = [...]string{
@@ -107,12 +124,20 @@ var modsStringFull = [...]string{
 	"ScoreV2",
 	"LastMod",
 	"Daycore",
+	"DifficultyAdjust",
+	"WindUp",
+	"WindDown",
+	"Mirror",
+	"Grow",
+	"Deflate",
+	"Transform",
+	"Wiggle",
 }
 
 func (mods Modifier) GetScoreMultiplier() float64 {
 	multiplier := 1.0
 
-	if mods&NoFail > 0 {
+	if mods&NoFail > 0 && mods&ScoreV2 == 0 {
 		multiplier *= 0.5
 	}
 
@@ -129,11 +154,19 @@ func (mods Modifier) GetScoreMultiplier() float64 {
 	}
 
 	if mods&HardRock > 0 {
-		multiplier *= 1.06
+		if mods&ScoreV2 > 0 {
+			multiplier *= 1.10
+		} else {
+			multiplier *= 1.06
+		}
 	}
 
 	if mods&DoubleTime > 0 {
-		multiplier *= 1.12
+		if mods&ScoreV2 > 0 {
+			multiplier *= 1.20
+		} else {
+			multiplier *= 1.12
+		}
 	}
 
 	if mods&Flashlight > 0 {
@@ -148,6 +181,14 @@ func (mods Modifier) GetScoreMultiplier() float64 {
 		multiplier *= 0.9
 	}
 
+	if mods&DifficultyAdjust > 0 {
+		multiplier *= 0.5
+	}
+
+	if mods&(WindUp|WindDown) > 0 {
+		multiplier *= 0.5
+	}
+
 	return multiplier
 }
 
@@ -246,7 +287,12 @@ func (mods Modifier) Compatible() bool {
 		((mods.Active(Perfect) || mods.Active(SuddenDeath)) && mods.Active(NoFail)) ||
 		(mods.Active(Relax) && mods.Active(Relax2)) ||
 		((mods.Active(Relax) || mods.Active(Relax2)) && (mods.Active(SuddenDeath) || mods.Active(Perfect) || mods.Active(Autoplay) || mods.Active(NoFail))) ||
-		(mods.Active(Relax2) && mods.Active(SpunOut)) {
+		(mods.Active(Relax2) && mods.Active(SpunOut)) ||
+		(mods.Active(WindUp) && mods.Active(WindDown)) ||
+		((mods.Active(WindUp) || mods.Active(WindDown)) && (mods.Active(DoubleTime) || mods.Active(HalfTime))) ||
+		(mods.Active(Mirror) && mods.Active(HardRock)) ||
+		(mods.Active(Grow) && mods.Active(Deflate)) ||
+		(mods.Active(Transform) && mods.Active(Wiggle)) {
 		return false
 	}
 
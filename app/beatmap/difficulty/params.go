@@ -0,0 +1,182 @@
+package difficulty
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Wind Up and Wind Down reach their final rate at 75% of the beatmap, same as in osu!lazer
+const rampFinalProgress = 0.75
+
+// ModParams holds settings of parametrised mods that can't be stored in Modifier bitmask
+type ModParams struct {
+	// Difficulty Adjust overrides, NaN keeps beatmap's value
+	HP, CS, OD, AR float64
+
+	// Playback rate, DoubleTime and HalfTime are applied on top of it
+	Rate float64
+
+	// Initial and final rate of Wind Up/Wind Down, NaN uses mod's default
+	InitialRate, FinalRate float64
+
+	// Axes reflected by Mirror, objects are flipped horizontally if neither is set
+	MirrorHorizontal, MirrorVertical bool
+
+	// Seed used by Random to place objects
+	RandomSeed int64
+}
+
+func NewModParams() ModParams {
+	return ModParams{
+		HP:          math.NaN(),
+		CS:          math.NaN(),
+		OD:          math.NaN(),
+		AR:          math.NaN(),
+		Rate:        1,
+		InitialRate: math.NaN(),
+		FinalRate:   math.NaN(),
+	}
+}
+
+// IsAdjusted returns true if any of beatmap's difficulty settings is overridden
+func (params ModParams) IsAdjusted() bool {
+	return !math.IsNaN(params.HP) || !math.IsNaN(params.CS) || !math.IsNaN(params.OD) || !math.IsNaN(params.AR)
+}
+
+// IsRamped returns true if Wind Up/Wind Down rates were given
+func (params ModParams) IsRamped() bool {
+	return !math.IsNaN(params.InitialRate) || !math.IsNaN(params.FinalRate)
+}
+
+func (diff *Difficulty) SetParams(params ModParams) {
+	diff.params = params
+	diff.calculate()
+}
+
+func (diff *Difficulty) GetParams() ModParams {
+	return diff.params
+}
+
+// SetRampRange sets the time range in which Wind Up/Wind Down change the rate, it has to be called after beatmap objects are parsed
+func (diff *Difficulty) SetRampRange(firstObjectStart, lastObjectEnd float64) {
+	diff.rampStart = firstObjectStart
+	diff.rampEnd = firstObjectStart + (lastObjectEnd-firstObjectStart)*rampFinalProgress
+}
+
+// getRampRates returns initial and final Wind Up/Wind Down rates, both are 1 if neither of them is active
+func (diff *Difficulty) getRampRates() (initial, final float64) {
+	initial, final = 1, 1
+
+	switch {
+	case diff.Mods&WindUp > 0:
+		final = 1.5
+	case diff.Mods&WindDown > 0:
+		final = 0.75
+	default:
+		return
+	}
+
+	if !math.IsNaN(diff.params.InitialRate) {
+		initial = diff.params.InitialRate
+	}
+
+	if !math.IsNaN(diff.params.FinalRate) {
+		final = diff.params.FinalRate
+	}
+
+	return
+}
+
+// getBaseSpeed returns rate of custom speed, DoubleTime and HalfTime combined
+func (diff *Difficulty) getBaseSpeed() float64 {
+	if diff.Mods&DoubleTime > 0 {
+		return 1.5 * diff.params.Rate
+	} else if diff.Mods&HalfTime > 0 {
+		return 0.75 * diff.params.Rate
+	}
+
+	return diff.params.Rate
+}
+
+func (diff *Difficulty) isRamping() bool {
+	initial, final := diff.getRampRates()
+	return initial != final && diff.rampEnd > diff.rampStart
+}
+
+// GetSpeedAt returns playback rate at the given beatmap time, it differs from Speed only when Wind Up/Wind Down is active
+func (diff *Difficulty) GetSpeedAt(time float64) float64 {
+	if !diff.isRamping() {
+		return diff.Speed
+	}
+
+	initial, final := diff.getRampRates()
+
+	progress := math.Max(0, math.Min(1, (time-diff.rampStart)/(diff.rampEnd-diff.rampStart)))
+
+	return diff.getBaseSpeed() * (initial + (final-initial)*progress)
+}
+
+// ToRealTime converts beatmap time to the time that passes on a wall clock since beatmap's 0ms
+func (diff *Difficulty) ToRealTime(time float64) float64 {
+	if !diff.isRamping() || time <= diff.rampStart {
+		return time / diff.Speed
+	}
+
+	initial, final := diff.getRampRates()
+
+	// Rate changes linearly during the ramp, so time spent in it is an integral of 1/rate
+	slope := (final - initial) / (diff.rampEnd - diff.rampStart)
+
+	realTime := diff.rampStart/initial + math.Log((initial+slope*(math.Min(time, diff.rampEnd)-diff.rampStart))/initial)/slope
+
+	if time > diff.rampEnd {
+		realTime += (time - diff.rampEnd) / final
+	}
+
+	return realTime / diff.getBaseSpeed()
+}
+
+// GetParamsString returns a short description of parametrised mods, e.g. "DA AR9.5 OD8, 1.15x"
+func (diff *Difficulty) GetParamsString() string {
+	var parts []string
+
+	if diff.params.IsAdjusted() {
+		s := "DA"
+
+		for _, p := range []struct {
+			name  string
+			value float64
+		}{{"AR", diff.params.AR}, {"CS", diff.params.CS}, {"OD", diff.params.OD}, {"HP", diff.params.HP}} {
+			if !math.IsNaN(p.value) {
+				s += " " + p.name + strconv.FormatFloat(p.value, 'f', -1, 64)
+			}
+		}
+
+		parts = append(parts, s)
+	}
+
+	if diff.params.Rate != 1 {
+		parts = append(parts, fmt.Sprintf("%.2fx", diff.params.Rate))
+	}
+
+	if diff.Mods&(WindUp|WindDown) > 0 {
+		initial, final := diff.getRampRates()
+		parts = append(parts, fmt.Sprintf("%s %.2fx-%.2fx", (diff.Mods&(WindUp|WindDown)).String(), initial, final))
+	}
+
+	if diff.Mods&Mirror > 0 && diff.params.MirrorVertical {
+		if diff.params.MirrorHorizontal {
+			parts = append(parts, "MR HV")
+		} else {
+			parts = append(parts, "MR V")
+		}
+	}
+
+	if diff.Mods&Random > 0 {
+		parts = append(parts, fmt.Sprintf("RN seed %d", diff.params.RandomSeed))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,245 @@
+package beatmap
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/animation/easing"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	wiggleDuration = 90.0
+	wiggleStrength = 10.0
+
+	// How many random angles Random tries before moving an object back into the playfield
+	randomAttempts = 10
+)
+
+// transformedObject is a circle or a slider with its position transform
+type transformedObject struct {
+	object    objects.IHitObject
+	transform *objects.ModTransform
+}
+
+var modTransforms = []struct {
+	mods  difficulty.Modifier
+	apply func(beatMap *BeatMap, objs []transformedObject)
+}{
+	{difficulty.Mirror, applyMirror},
+	{difficulty.Random, applyRandom},
+	{difficulty.Grow | difficulty.Deflate, applyScaleTween},
+	{difficulty.Transform, applyTransformAnimation},
+	{difficulty.Wiggle, applyWiggle},
+}
+
+// applyModTransforms runs position mods on osu!standard objects, it has to be called after stacking is calculated
+func applyModTransforms(beatMap *BeatMap) {
+	if beatMap.Mode != 0 {
+		return
+	}
+
+	var objs []transformedObject
+
+	for _, o := range beatMap.HitObjects {
+		transform := new(objects.ModTransform)
+
+		switch obj := o.(type) {
+		case *objects.Circle:
+			obj.ModTransform = transform
+		case *objects.Slider:
+			obj.ModTransform = transform
+		default:
+			continue
+		}
+
+		objs = append(objs, transformedObject{o, transform})
+	}
+
+	for _, t := range modTransforms {
+		if beatMap.Diff.Mods&t.mods > 0 {
+			t.apply(beatMap, objs)
+		}
+	}
+}
+
+func applyMirror(beatMap *BeatMap, objs []transformedObject) {
+	params := beatMap.Diff.GetParams()
+
+	for _, o := range objs {
+		o.transform.FlipX = params.MirrorHorizontal || !params.MirrorVertical
+		o.transform.FlipY = params.MirrorVertical
+	}
+}
+
+// applyRandom rotates every jump by a random angle, distances between objects are kept unless an object would leave the playfield
+func applyRandom(beatMap *BeatMap, objs []transformedObject) {
+	rng := rand.New(rand.NewSource(beatMap.Diff.GetParams().RandomSeed))
+
+	var previousEnd, previousNewEnd vector.Vector2f
+
+	for i, o := range objs {
+		start := o.transform.Apply(o.object.GetStartPosition())
+		end := o.transform.Apply(o.object.GetEndPosition())
+
+		if i > 0 {
+			minBound, maxBound := getBounds(o)
+
+			distance := float64(start.Dst(previousEnd))
+
+			var newStart vector.Vector2f
+
+			for attempt := 0; attempt < randomAttempts; attempt++ {
+				angle := rng.Float64() * 2 * math.Pi
+
+				newStart = previousNewEnd.Add(vector.NewVec2d(math.Cos(angle), math.Sin(angle)).Scl(distance).Copy32())
+
+				if isInPlayfield(newStart.Add(minBound), newStart.Add(maxBound)) {
+					break
+				}
+			}
+
+			newStart.X = clampToPlayfield(newStart.X, minBound.X, maxBound.X, 512)
+			newStart.Y = clampToPlayfield(newStart.Y, minBound.Y, maxBound.Y, 384)
+
+			o.transform.Offset = o.transform.Offset.Add(newStart.Sub(start))
+		}
+
+		previousEnd = end
+		previousNewEnd = o.transform.Apply(o.object.GetEndPosition())
+	}
+}
+
+// getBounds returns top left and bottom right corners of object's path relative to its start position
+func getBounds(o transformedObject) (minBound, maxBound vector.Vector2f) {
+	slider, ok := o.object.(*objects.Slider)
+	if !ok {
+		return
+	}
+
+	start := o.transform.Apply(slider.GetStartPosition())
+
+	for t := float32(0); t <= 1; t += 0.05 {
+		point := o.transform.Apply(slider.GetCurve().PointAt(t)).Sub(start)
+
+		minBound.X = float32(math.Min(float64(minBound.X), float64(point.X)))
+		minBound.Y = float32(math.Min(float64(minBound.Y), float64(point.Y)))
+		maxBound.X = float32(math.Max(float64(maxBound.X), float64(point.X)))
+		maxBound.Y = float32(math.Max(float64(maxBound.Y), float64(point.Y)))
+	}
+
+	return
+}
+
+func isInPlayfield(topLeft, bottomRight vector.Vector2f) bool {
+	return topLeft.X >= 0 && topLeft.Y >= 0 && bottomRight.X <= 512 && bottomRight.Y <= 384
+}
+
+// clampToPlayfield moves a coordinate so object's path fits between 0 and size if possible
+func clampToPlayfield(value, minBound, maxBound, size float32) float32 {
+	if value+maxBound > size {
+		value = size - maxBound
+	}
+
+	if value+minBound < 0 {
+		value = -minBound
+	}
+
+	return value
+}
+
+// applyScaleTween scales hit circles from 0.5 (Grow) or 2 (Deflate) to 1 while they approach
+func applyScaleTween(beatMap *BeatMap, objs []transformedObject) {
+	startScale := 0.5
+	if beatMap.Diff.Mods.Active(difficulty.Deflate) {
+		startScale = 2
+	}
+
+	preempt := beatMap.Diff.Preempt
+
+	for _, o := range objs {
+		appearTime := o.object.GetStartTime() - preempt
+
+		o.transform.Scale = func(time float64) float64 {
+			progress := math.Max(0, math.Min(1, (time-appearTime)/preempt))
+			return startScale + (1-startScale)*easing.OutSine(progress)
+		}
+	}
+}
+
+// applyTransformAnimation moves objects in from a direction that rotates with every object
+func applyTransformAnimation(beatMap *BeatMap, objs []transformedObject) {
+	preempt := beatMap.Diff.Preempt
+	fadeIn := beatMap.Diff.FadeIn
+
+	appearDistance := (preempt - fadeIn) / 2
+
+	theta := 0.0
+
+	for _, o := range objs {
+		appearOffset := vector.NewVec2d(math.Cos(theta), math.Sin(theta)).Scl(appearDistance).Copy32()
+
+		appearTime := o.object.GetStartTime() - preempt - 1
+		moveDuration := preempt + 1
+
+		o.transform.Animation = func(time float64) vector.Vector2f {
+			progress := math.Max(0, math.Min(1, (time-appearTime)/moveDuration))
+			return appearOffset.Scl(float32(1 - easing.InOutSine(progress)))
+		}
+
+		theta += fadeIn / 1000
+	}
+}
+
+type wigglePoint struct {
+	time   float64
+	offset vector.Vector2f
+}
+
+// applyWiggle moves objects to random points around their position every 90ms while they approach and while sliders are active
+func applyWiggle(beatMap *BeatMap, objs []transformedObject) {
+	preempt := beatMap.Diff.Preempt
+
+	for _, o := range objs {
+		rng := rand.New(rand.NewSource(int64(o.object.GetStartTime())))
+
+		points := []wigglePoint{{o.object.GetStartTime() - preempt, vector.NewVec2f(0, 0)}}
+
+		wiggle := func(time float64) {
+			angle := rng.Float64() * 2 * math.Pi
+			distance := rng.Float64() * wiggleStrength
+
+			points = append(points, wigglePoint{time + wiggleDuration, vector.NewVec2d(math.Cos(angle), math.Sin(angle)).Scl(distance).Copy32()})
+		}
+
+		for i := 0; i < int(preempt/wiggleDuration); i++ {
+			wiggle(o.object.GetStartTime() - preempt + float64(i)*wiggleDuration)
+		}
+
+		for i := 0; i < int(o.object.GetDuration()/wiggleDuration); i++ {
+			wiggle(o.object.GetStartTime() + float64(i)*wiggleDuration)
+		}
+
+		o.transform.Animation = func(time float64) vector.Vector2f {
+			index := sort.Search(len(points), func(i int) bool {
+				return points[i].time >= time
+			})
+
+			if index == 0 {
+				return points[0].offset
+			} else if index == len(points) {
+				return points[len(points)-1].offset
+			}
+
+			// Every move starts wiggleDuration before it reaches its point
+			next := points[index]
+			previous := points[index-1]
+
+			progress := math.Max(0, math.Min(1, 1-(next.time-time)/wiggleDuration))
+
+			return previous.offset.Lerp(next.offset, float32(progress))
+		}
+	}
+}
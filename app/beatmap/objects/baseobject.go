@@ -19,6 +19,7 @@ func commonParse(data []string, extraIndex int) *HitObject {
 	y, _ := strconv.ParseFloat(data[1], 32)
 	time, _ := strconv.ParseInt(data[2], 10, 64)
 	objType, _ := strconv.ParseInt(data[3], 10, 64)
+	hitSound, _ := strconv.ParseInt(data[4], 10, 64)
 
 	startPos := vector.NewVec2f(float32(x), float32(y))
 
@@ -30,6 +31,7 @@ func commonParse(data []string, extraIndex int) *HitObject {
 		HitObjectID: -1,
 		NewCombo:    (objType & 4) == 4,
 		ColorOffset: (objType >> 4) & 7,
+		HitSound:    int(hitSound),
 	}
 
 	hitObject.BasicHitSound = parseExtras(data, extraIndex)
@@ -169,7 +169,7 @@ func (circle *Circle) SetDifficulty(diff *difficulty.Difficulty) {
 	circle.approachCircle = sprite.NewSpriteSingle(skin.GetTexture("approachcircle"), 0, vector.NewVec2d(0, 0), bmath.Origin.Centre)
 	circle.reverseArrow = sprite.NewSpriteSingle(skin.GetTexture("reversearrow"), 0, vector.NewVec2d(0, 0), bmath.Origin.Centre)
 
-	circle.sprites = append(circle.sprites, circle.hitCircle, circle.hitCircleOverlay, circle.approachCircle, circle.reverseArrow)
+	circle.sprites = append(circle.sprites[:0], circle.hitCircle, circle.hitCircleOverlay, circle.approachCircle, circle.reverseArrow)
 
 	circle.hitCircle.SetAlpha(0)
 	circle.hitCircleOverlay.SetAlpha(0)
@@ -267,7 +267,13 @@ func (circle *Circle) UpdateStacking() {
 func (circle *Circle) Draw(time float64, color color2.Color, batch *batch.QuadBatch) bool {
 	position := circle.GetStackedPositionAtMod(time, circle.diff.Mods)
 
-	batch.SetSubScale(1, 1)
+	// Grow and Deflate don't scale repeat arrows and slider ends
+	scale := 1.0
+	if !circle.SliderPoint || circle.SliderPointStart {
+		scale = circle.ModTransform.GetScale(time)
+	}
+
+	batch.SetSubScale(scale, scale)
 	batch.SetTranslation(position.Copy64())
 
 	alpha := float64(color.A)
@@ -301,7 +307,7 @@ func (circle *Circle) Draw(time float64, color color2.Color, batch *batch.QuadBa
 			circle.reverseArrow.Draw(time, batch)
 		}
 
-		batch.SetSubScale(1, 1)
+		batch.SetSubScale(scale, scale)
 		batch.SetTranslation(position.Copy64())
 		batch.SetColor(1, 1, 1, alpha)
 		if skin.GetInfo().HitCircleOverlayAboveNumber {
@@ -45,6 +45,7 @@ type IHitObject interface {
 	GetType() Type
 
 	DisableAudioSubmission(value bool)
+	IsAudioSubmissionDisabled() bool
 }
 
 type ILongObject interface {
@@ -74,6 +75,8 @@ type HitObject struct {
 
 	PositionDelegate func(time float64) vector.Vector2f
 
+	ModTransform *ModTransform
+
 	StackIndex   int64
 	StackIndexEZ int64
 	StackIndexHR int64
@@ -87,6 +90,7 @@ type HitObject struct {
 	ColorOffset int64
 
 	BasicHitSound audio.HitSoundInfo
+	HitSound      int // raw hitsound flags: 2 - whistle, 4 - finish, 8 - clap
 	audioSubmissionDisabled bool
 }
 
@@ -119,21 +123,11 @@ func (hitObject *HitObject) GetPositionAt(time float64) vector.Vector2f {
 }
 
 func (hitObject *HitObject) GetStackedPositionAt(time float64) vector.Vector2f {
-	return hitObject.GetPositionAt(time).Add(hitObject.StackOffset)
+	return hitObject.modifyPosition(hitObject.GetPositionAt(time), time, difficulty.None)
 }
 
 func (hitObject *HitObject) GetStackedPositionAtMod(time float64, modifier difficulty.Modifier) vector.Vector2f {
-	basePosition := hitObject.GetPositionAt(time)
-
-	switch {
-	case modifier&difficulty.HardRock > 0:
-		basePosition.Y = 384 - basePosition.Y
-		return basePosition.Add(hitObject.StackOffsetHR)
-	case modifier&difficulty.Easy > 0:
-		return basePosition.Add(hitObject.StackOffsetEZ)
-	}
-
-	return basePosition.Add(hitObject.StackOffset)
+	return hitObject.modifyPosition(hitObject.GetPositionAt(time), time, modifier)
 }
 
 func (hitObject *HitObject) GetStartPosition() vector.Vector2f {
@@ -141,21 +135,11 @@ func (hitObject *HitObject) GetStartPosition() vector.Vector2f {
 }
 
 func (hitObject *HitObject) GetStackedStartPosition() vector.Vector2f {
-	return hitObject.GetStartPosition().Add(hitObject.StackOffset)
+	return hitObject.modifyPosition(hitObject.GetStartPosition(), hitObject.StartTime, difficulty.None)
 }
 
 func (hitObject *HitObject) GetStackedStartPositionMod(modifier difficulty.Modifier) vector.Vector2f {
-	basePosition := hitObject.GetStartPosition()
-
-	switch {
-	case modifier&difficulty.HardRock > 0:
-		basePosition.Y = 384 - basePosition.Y
-		return basePosition.Add(hitObject.StackOffsetHR)
-	case modifier&difficulty.Easy > 0:
-		return basePosition.Add(hitObject.StackOffsetEZ)
-	}
-
-	return basePosition.Add(hitObject.StackOffset)
+	return hitObject.modifyPosition(hitObject.GetStartPosition(), hitObject.StartTime, modifier)
 }
 
 func (hitObject *HitObject) GetEndPosition() vector.Vector2f {
@@ -163,21 +147,16 @@ func (hitObject *HitObject) GetEndPosition() vector.Vector2f {
 }
 
 func (hitObject *HitObject) GetStackedEndPosition() vector.Vector2f {
-	return hitObject.GetEndPosition().Add(hitObject.StackOffset)
+	return hitObject.modifyPosition(hitObject.GetEndPosition(), hitObject.EndTime, difficulty.None)
 }
 
 func (hitObject *HitObject) GetStackedEndPositionMod(modifier difficulty.Modifier) vector.Vector2f {
-	basePosition := hitObject.GetEndPosition()
-
-	switch {
-	case modifier&difficulty.HardRock > 0:
-		basePosition.Y = 384 - basePosition.Y
-		return basePosition.Add(hitObject.StackOffsetHR)
-	case modifier&difficulty.Easy > 0:
-		return basePosition.Add(hitObject.StackOffsetEZ)
-	}
+	return hitObject.modifyPosition(hitObject.GetEndPosition(), hitObject.EndTime, modifier)
+}
 
-	return basePosition.Add(hitObject.StackOffset)
+// modifyPosition applies position mods, HardRock's flip and stacking to a raw position
+func (hitObject *HitObject) modifyPosition(basePosition vector.Vector2f, time float64, modifier difficulty.Modifier) vector.Vector2f {
+	return ModifyPosition(hitObject, basePosition, modifier).Add(hitObject.ModTransform.GetAnimationOffset(time))
 }
 
 func (hitObject *HitObject) GetID() int64 {
@@ -249,7 +228,13 @@ func (hitObject *HitObject) DisableAudioSubmission(value bool) {
 	hitObject.audioSubmissionDisabled = value
 }
 
+func (hitObject *HitObject) IsAudioSubmissionDisabled() bool {
+	return hitObject.audioSubmissionDisabled
+}
+
 func ModifyPosition(hitObject *HitObject, basePosition vector.Vector2f, modifier difficulty.Modifier) vector.Vector2f {
+	basePosition = hitObject.ModTransform.Apply(basePosition)
+
 	switch {
 	case modifier&difficulty.HardRock > 0:
 		basePosition.Y = 384 - basePosition.Y
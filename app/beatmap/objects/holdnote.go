@@ -0,0 +1,69 @@
+package objects
+
+import (
+	"github.com/wieku/danser-go/app/audio"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HoldNote is osu!mania's long note, it's not rendered or played by osu!standard parts of danser
+type HoldNote struct {
+	*HitObject
+
+	sample  int
+	Timings *Timings
+}
+
+func NewHoldNote(data []string) *HoldNote {
+	note := &HoldNote{
+		HitObject: commonParse(data, len(data)),
+	}
+
+	f, _ := strconv.ParseInt(data[4], 10, 64)
+	note.sample = int(f)
+
+	// Hold notes store end time as a first element of extras: endTime:sampleSet:additionSet:index:volume:filename
+	if len(data) > 5 {
+		extras := strings.SplitN(data[5], ":", 2)
+
+		note.EndTime, _ = strconv.ParseFloat(extras[0], 64)
+
+		if len(extras) > 1 {
+			note.BasicHitSound = parseExtras(extras[1:], 0)
+		}
+	}
+
+	note.EndTime = math.Max(note.StartTime, note.EndTime)
+
+	return note
+}
+
+func (note *HoldNote) SetTiming(timings *Timings) {
+	note.Timings = timings
+}
+
+func (note *HoldNote) PlaySound() {
+	if note.audioSubmissionDisabled || note.Timings == nil {
+		return
+	}
+
+	point := note.Timings.GetPoint(note.StartTime)
+
+	index := note.BasicHitSound.CustomIndex
+	sampleSet := note.BasicHitSound.SampleSet
+
+	if index == 0 {
+		index = point.SampleIndex
+	}
+
+	if sampleSet == 0 {
+		sampleSet = point.SampleSet
+	}
+
+	audio.PlaySample(sampleSet, note.BasicHitSound.AdditionSet, note.sample, index, point.SampleVolume, note.HitObjectID, note.GetStackedStartPosition().X64())
+}
+
+func (note *HoldNote) GetType() Type {
+	return LONGNOTE
+}
@@ -0,0 +1,71 @@
+package objects
+
+import (
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+// ModTransform changes object's position and size, it's created by position mods after stacking is calculated
+type ModTransform struct {
+	// Reflect object around playfield's centre
+	FlipX, FlipY bool
+
+	// Offset added to object's position after flipping
+	Offset vector.Vector2f
+
+	// Animation returns an offset of object's position at the given time
+	Animation func(time float64) vector.Vector2f
+
+	// Scale returns hit circle's scale at the given time
+	Scale func(time float64) float64
+}
+
+// Apply flips and moves a position, animations are not applied
+func (transform *ModTransform) Apply(position vector.Vector2f) vector.Vector2f {
+	if transform == nil {
+		return position
+	}
+
+	if transform.FlipX {
+		position.X = 512 - position.X
+	}
+
+	if transform.FlipY {
+		position.Y = 384 - position.Y
+	}
+
+	return position.Add(transform.Offset)
+}
+
+// ApplyAngle changes direction of an angle the same way Apply changes positions
+func (transform *ModTransform) ApplyAngle(angle float32) float32 {
+	if transform == nil {
+		return angle
+	}
+
+	if transform.FlipX {
+		angle = math32.Pi - angle
+	}
+
+	if transform.FlipY {
+		angle = -angle
+	}
+
+	return angle
+}
+
+func (transform *ModTransform) GetAnimationOffset(time float64) vector.Vector2f {
+	if transform == nil || transform.Animation == nil {
+		return vector.NewVec2f(0, 0)
+	}
+
+	return transform.Animation(time)
+}
+
+func (transform *ModTransform) GetScale(time float64) float64 {
+	if transform == nil || transform.Scale == nil {
+		return 1
+	}
+
+	return transform.Scale(time)
+}
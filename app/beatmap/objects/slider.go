@@ -152,7 +152,7 @@ func NewSlider(data []string) *Slider {
 }
 
 func (slider *Slider) GetHalf() vector.Vector2f {
-	return slider.multiCurve.PointAt(0.5).Add(slider.StackOffset)
+	return slider.ModTransform.Apply(slider.multiCurve.PointAt(0.5)).Add(slider.StackOffset)
 }
 
 func (slider *Slider) GetStartAngle() float32 {
@@ -175,6 +175,21 @@ func (slider *Slider) GetPartLen() float32 {
 	return float32(20.0) / float32(slider.Timings.GetSliderTimeP(slider.TPoint, slider.pixelLength)) * float32(slider.pixelLength)
 }
 
+// GetCurve returns the path of the slider, its length is equal to slider's pixel length
+func (slider *Slider) GetCurve() *curves.MultiCurve {
+	return slider.multiCurve
+}
+
+// GetPixelLength returns length of a single span in osu!pixels as given in the beatmap
+func (slider *Slider) GetPixelLength() float64 {
+	return slider.pixelLength
+}
+
+// GetRepeats returns the amount of spans of the slider
+func (slider *Slider) GetRepeats() int64 {
+	return slider.repeat
+}
+
 func (slider *Slider) PositionAt(time float64) vector.Vector2f {
 	if slider.IsRetarded() {
 		return slider.StartPosRaw
@@ -222,6 +237,7 @@ func (slider *Slider) createDummyCircle(time float64, inheritStart, inheritEnd b
 	circle.StackOffset = slider.StackOffset
 	circle.StackOffsetHR = slider.StackOffsetHR
 	circle.StackOffsetEZ = slider.StackOffsetEZ
+	circle.ModTransform = slider.ModTransform
 
 	return circle
 }
@@ -382,9 +398,14 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 	slider.startCircle.StackOffset = slider.StackOffset
 	slider.startCircle.StackOffsetHR = slider.StackOffsetHR
 	slider.startCircle.StackOffsetEZ = slider.StackOffsetEZ
+	slider.startCircle.ModTransform = slider.ModTransform
 	slider.startCircle.SetDifficulty(diff)
 
-	slider.edges = append(slider.edges, slider.startCircle)
+	slider.edges = []*Circle{slider.startCircle}
+	slider.endCircles = slider.endCircles[:0]
+	slider.headEndCircles = slider.headEndCircles[:0]
+	slider.tailEndCircles = slider.tailEndCircles[:0]
+	slider.isSliding = false
 
 	sixty := 1000.0 / 60
 	frameDelay := math.Max(150/slider.Timings.GetVelocity(slider.TPoint)*sixty, sixty)
@@ -421,6 +442,7 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 		circle.StackOffset = slider.StackOffset
 		circle.StackOffsetHR = slider.StackOffsetHR
 		circle.StackOffsetEZ = slider.StackOffsetEZ
+		circle.ModTransform = slider.ModTransform
 		circle.SetTiming(slider.Timings)
 		circle.SetDifficulty(diff)
 
@@ -445,6 +467,9 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 
 		endTime := math.Min(a+150, p.Time-36)
 
+		p.scale = animation.NewGlider(0.0)
+		p.fade = animation.NewGlider(0.0)
+
 		p.scale.AddEventS(a, endTime, 0.5, 1.2)
 		p.scale.AddEventSEase(endTime, endTime+150, 1.2, 1.0, easing.OutQuad)
 		p.fade.AddEventS(a, endTime, 0.0, 1.0)
@@ -466,7 +491,19 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 		slider.TickReverse[i] = p
 	}
 
-	slider.body = sliderrenderer.NewBody(slider.multiCurve, diff.Mods&difficulty.HardRock > 0, float32(slider.diff.CircleRadius))
+	if slider.body != nil {
+		slider.body.Dispose()
+	}
+
+	slider.body = sliderrenderer.NewBody(slider.multiCurve, func(point vector.Vector2f) vector.Vector2f {
+		point = slider.ModTransform.Apply(point)
+
+		if diff.Mods&difficulty.HardRock > 0 {
+			point.Y = 384 - point.Y
+		}
+
+		return point
+	}, float32(slider.diff.CircleRadius))
 }
 
 func (slider *Slider) IsRetarded() bool {
@@ -528,8 +565,8 @@ func (slider *Slider) Update(time float64) bool {
 
 	headPos := slider.multiCurve.PointAt(float32(slider.sliderSnakeHead.GetValue()))
 	tailPos := slider.multiCurve.PointAt(float32(slider.sliderSnakeTail.GetValue()))
-	headAngle := slider.multiCurve.GetStartAngleAt(float32(slider.sliderSnakeHead.GetValue())) + math.Pi
-	tailAngle := slider.multiCurve.GetEndAngleAt(float32(slider.sliderSnakeTail.GetValue())) + math.Pi
+	headAngle := slider.ModTransform.ApplyAngle(slider.multiCurve.GetStartAngleAt(float32(slider.sliderSnakeHead.GetValue())) + math.Pi)
+	tailAngle := slider.ModTransform.ApplyAngle(slider.multiCurve.GetEndAngleAt(float32(slider.sliderSnakeTail.GetValue())) + math.Pi)
 
 	if slider.diff.Mods&difficulty.HardRock > 0 {
 		headAngle = -headAngle
@@ -779,7 +816,7 @@ func (slider *Slider) DrawBodyBase(_ float64, projection mgl32.Mat4) {
 	slider.body.DrawBase(slider.sliderSnakeHead.GetValue(), slider.sliderSnakeTail.GetValue(), projection)
 }
 
-func (slider *Slider) DrawBody(_ float64, bodyColor, innerBorder, outerBorder color2.Color, projection mgl32.Mat4, scale float32) {
+func (slider *Slider) DrawBody(time float64, bodyColor, innerBorder, outerBorder color2.Color, projection mgl32.Mat4, scale float32) {
 	colorAlpha := slider.bodyFade.GetValue() * float64(bodyColor.A)
 
 	bodyOpacityInner := bmath.ClampF32(float32(settings.Objects.Colors.Sliders.Body.InnerAlpha), 0.0, 1.0)
@@ -837,7 +874,7 @@ func (slider *Slider) DrawBody(_ float64, bodyColor, innerBorder, outerBorder co
 		stackOffset = slider.StackOffsetEZ
 	}
 
-	slider.body.DrawNormal(projection, stackOffset, scale, bodyInner, bodyOuter, borderInner, borderOuter)
+	slider.body.DrawNormal(projection, stackOffset.Add(slider.ModTransform.GetAnimationOffset(time)), scale, bodyInner, bodyOuter, borderInner, borderOuter)
 }
 
 func (slider *Slider) Draw(time float64, color color2.Color, batch *batch.QuadBatch) bool {
@@ -864,7 +901,10 @@ func (slider *Slider) Draw(time float64, color color2.Color, batch *batch.QuadBa
 					al := p.fade.GetValue()
 
 					if al > 0.001 {
-						batch.SetTranslation(p.Pos.Copy64())
+						// Ticks follow slider's animation, their positions were calculated at the time they're hit
+						animationShift := slider.ModTransform.GetAnimationOffset(time).Sub(slider.ModTransform.GetAnimationOffset(p.Time))
+
+						batch.SetTranslation(p.Pos.Add(animationShift).Copy64())
 						batch.SetSubScale(p.scale.GetValue(), p.scale.GetValue())
 
 						if settings.Objects.Colors.Sliders.WhiteScorePoints || settings.Skin.UseColorsFromSkin {
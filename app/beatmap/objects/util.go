@@ -17,6 +17,8 @@ func CreateObject(data []string) IHitObject {
 		}
 	} else if (objType & SLIDER) > 0 {
 		return NewSlider(data)
+	} else if (objType & LONGNOTE) > 0 {
+		return NewHoldNote(data)
 	}
 
 	return nil
@@ -351,5 +351,10 @@ func ParseObjects(beatMap *BeatMap) {
 		obj.SetTiming(beatMap.Timings)
 	}
 
+	if len(beatMap.HitObjects) > 0 {
+		beatMap.Diff.SetRampRange(beatMap.HitObjects[0].GetStartTime(), beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime())
+	}
+
 	calculateStackLeniency(beatMap)
+	applyModTransforms(beatMap)
 }
@@ -0,0 +1,221 @@
+package dance
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"math"
+	"strings"
+)
+
+type catchFrame struct {
+	time int64
+	x    float64
+	dash bool
+}
+
+// CatchController plays osu!catch beatmaps using a replay given by -replay or autoplay if it's not specified
+type CatchController struct {
+	bMap    *beatmap.BeatMap
+	cursors []*graphics.Cursor
+	ruleset *catch.CatchRuleSet
+
+	frames     []catchFrame
+	frameIndex int
+}
+
+func NewCatchController() *CatchController {
+	return new(CatchController)
+}
+
+func (controller *CatchController) SetBeatMap(beatMap *beatmap.BeatMap) {
+	controller.bMap = beatMap
+}
+
+func (controller *CatchController) InitCursors() {
+	cursor := graphics.NewCursor()
+	cursor.IsPlayer = true
+	cursor.SetPos(vector.NewVec2f(256, 192))
+
+	controller.cursors = []*graphics.Cursor{cursor}
+
+	mods := controller.bMap.Diff.Mods
+
+	if settings.REPLAY != "" {
+		replay := loadCatchReplay(settings.REPLAY, controller.bMap)
+
+		cursor.Name = replay.Username
+		cursor.ScoreTime = replay.Timestamp
+		mods = difficulty.Modifier(replay.Mods)
+
+		controller.frames = convertCatchFrames(replay.ReplayData)
+	} else {
+		cursor.Name = "danser"
+		cursor.IsAutoplay = true
+	}
+
+	controller.ruleset = catch.NewCatchRuleset(controller.bMap, controller.cursors, []difficulty.Modifier{mods})
+
+	if cursor.IsAutoplay {
+		controller.frames = createCatchAutoplay(controller.ruleset)
+	}
+
+	settings.PLAYERS = 1
+}
+
+func loadCatchReplay(path string, beatMap *beatmap.BeatMap) *rplpa.Replay {
+	log.Println("Loading: ", path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		panic(err)
+	}
+
+	if replay.PlayMode != 2 {
+		panic("Replay is not an osu!catch replay")
+	}
+
+	if !strings.EqualFold(replay.BeatmapMD5, beatMap.MD5) {
+		log.Println("Replay was made on a different version of the beatmap!")
+	}
+
+	if len(replay.ReplayData) == 0 {
+		panic("Replay is missing input data")
+	}
+
+	return replay
+}
+
+// convertCatchFrames converts relative replay frames to absolute ones, osu!catch stores catcher position in X coordinate and dash as left click
+func convertCatchFrames(data []*rplpa.ReplayData) (frames []catchFrame) {
+	time := int64(0)
+
+	for _, frame := range data {
+		if frame.Time == -12345 {
+			continue
+		}
+
+		time += frame.Time
+
+		frames = append(frames, catchFrame{time, float64(frame.MouseX), frame.KeyPressed.LeftClick})
+	}
+
+	return
+}
+
+// createCatchAutoplay moves the catcher to every object, walking when possible and dashing or hyperdashing when needed
+func createCatchAutoplay(ruleset *catch.CatchRuleSet) (frames []catchFrame) {
+	lastPosition := catch.PlayfieldWidth / 2
+	lastTime := 0.0
+
+	addFrame := func(time float64, x float64, dash bool) {
+		frames = append(frames, catchFrame{int64(time), x, dash})
+	}
+
+	const halfCatcherWidth = 106.75 * 0.3 * 0.5
+
+	var hyperDashTarget *catch.Object
+
+	for _, object := range ruleset.GetObjects() {
+		hyperDash := hyperDashTarget == object
+
+		if object.IsPalpable() {
+			hyperDashTarget = object.HyperDashTarget
+		}
+
+		startTime := float64(object.StartTime)
+
+		positionChange := math.Abs(lastPosition - object.X)
+		timeAvailable := startTime - lastTime
+
+		if timeAvailable < 0 {
+			continue
+		}
+
+		speedRequired := 0.0
+		if positionChange > 0 {
+			speedRequired = positionChange / timeAvailable
+		}
+
+		if lastPosition-halfCatcherWidth < object.X && lastPosition+halfCatcherWidth > object.X {
+			// Catcher is already in the correct range
+			lastTime = startTime
+			addFrame(startTime, lastPosition, false)
+
+			continue
+		}
+
+		switch {
+		case speedRequired > catch.BaseDashSpeed:
+			addFrame(startTime, object.X, false)
+		case hyperDash:
+			addFrame(startTime-timeAvailable, lastPosition, false)
+			addFrame(startTime, object.X, false)
+		case speedRequired > catch.BaseWalkSpeed:
+			// Dash for a part of the distance, then walk the rest
+			timeAtNormalSpeed := positionChange / catch.BaseWalkSpeed
+			timeAtDashSpeed := (timeAtNormalSpeed - timeAvailable) / 2
+
+			midPosition := lastPosition + (object.X-lastPosition)*timeAtDashSpeed/timeAvailable
+
+			addFrame(startTime-timeAvailable+1, lastPosition, true)
+			addFrame(startTime-timeAvailable+timeAtDashSpeed, midPosition, false)
+			addFrame(startTime, object.X, false)
+		default:
+			addFrame(startTime-positionChange/catch.BaseWalkSpeed, lastPosition, false)
+			addFrame(startTime, object.X, false)
+		}
+
+		lastTime = startTime
+		lastPosition = object.X
+	}
+
+	return
+}
+
+func (controller *CatchController) Update(time float64, delta float64) {
+	cursor := controller.cursors[0]
+
+	for controller.frameIndex < len(controller.frames) && controller.frames[controller.frameIndex].time <= int64(time) {
+		frame := controller.frames[controller.frameIndex]
+		controller.ruleset.UpdatePosition(cursor, frame.time, frame.x, frame.dash)
+
+		controller.frameIndex++
+	}
+
+	// Catcher moves smoothly between frames
+	if controller.frameIndex > 0 && controller.frameIndex < len(controller.frames) {
+		previous := controller.frames[controller.frameIndex-1]
+		next := controller.frames[controller.frameIndex]
+
+		x := previous.x
+		if next.time > previous.time {
+			x += (next.x - previous.x) * (time - float64(previous.time)) / float64(next.time-previous.time)
+		}
+
+		controller.ruleset.UpdatePosition(cursor, int64(time), x, previous.dash)
+	}
+
+	controller.ruleset.Update(int64(time))
+
+	cursor.Update(delta)
+}
+
+func (controller *CatchController) GetCursors() []*graphics.Cursor {
+	return controller.cursors
+}
+
+func (controller *CatchController) GetRuleset() *catch.CatchRuleSet {
+	return controller.ruleset
+}
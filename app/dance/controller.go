@@ -2,12 +2,16 @@ package dance
 
 import (
 	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/dance/movers"
 	"github.com/wieku/danser-go/app/dance/schedulers"
 	"github.com/wieku/danser-go/app/dance/spinners"
 	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"math"
 	"strings"
 )
 
@@ -18,10 +22,25 @@ type Controller interface {
 	GetCursors() []*graphics.Cursor
 }
 
+// Resettable is implemented by controllers that can play the beatmap again from the beginning
+type Resettable interface {
+	Reset()
+}
+
+// ReplaySaver is implemented by controllers that can export their session as an osu! replay
+type ReplaySaver interface {
+	SaveReplay() string
+}
+
 type GenericController struct {
 	bMap       *beatmap.BeatMap
 	cursors    []*graphics.Cursor
 	schedulers []schedulers.Scheduler
+	recorder   *ReplayRecorder
+
+	// ruleset judges the recorded cursor so exported replay has real score and hit counts
+	ruleset     *osu.OsuRuleSet
+	rulesetTime float64
 }
 
 func NewGenericController() Controller {
@@ -36,10 +55,28 @@ func (controller *GenericController) InitCursors() {
 	controller.cursors = make([]*graphics.Cursor, settings.TAG)
 	controller.schedulers = make([]schedulers.Scheduler, settings.TAG)
 
-	// Mover initialization
 	for i := range controller.cursors {
 		controller.cursors[i] = graphics.NewCursor()
+	}
+
+	controller.initSchedulers()
 
+	if settings.SAVEREPLAY && !settings.KNOCKOUT {
+		controller.recorder = NewReplayRecorder(controller.cursors[0])
+		controller.ruleset = osu.NewOsuRuleset(controller.bMap, controller.cursors[:1], []difficulty.Modifier{controller.bMap.Diff.Mods})
+		controller.ruleset.SetSilent(true)
+		controller.rulesetTime = math.Inf(-1)
+	}
+}
+
+// Reset starts cursor movement from the beginning of the beatmap again
+func (controller *GenericController) Reset() {
+	controller.initSchedulers()
+}
+
+func (controller *GenericController) initSchedulers() {
+	// Mover initialization
+	for i := range controller.cursors {
 		mover := "flower"
 		if len(settings.Dance.Movers) > 0 {
 			mover = strings.ToLower(settings.Dance.Movers[i%len(settings.Dance.Movers)])
@@ -137,6 +174,44 @@ func (controller *GenericController) Update(time float64, delta float64) {
 		controller.cursors[i].LeftButton = controller.cursors[i].LeftKey || controller.cursors[i].LeftMouse
 		controller.cursors[i].RightButton = controller.cursors[i].RightKey || controller.cursors[i].RightMouse
 	}
+
+	if controller.recorder != nil {
+		controller.updateRecorder(time)
+	}
+}
+
+func (controller *GenericController) updateRecorder(time float64) {
+	cursor := controller.cursors[0]
+
+	// Only frames written to the replay are judged, like while watching it later
+	cursor.IsReplayFrame = controller.recorder.Update(time)
+
+	// Replay can't go back in time, frames simulated again after a reset were already judged
+	if time <= controller.rulesetTime {
+		cursor.IsReplayFrame = false
+		return
+	}
+
+	controller.ruleset.UpdateClickFor(cursor, int64(time))
+	controller.ruleset.UpdateNormalFor(cursor, int64(time))
+	controller.ruleset.UpdatePostFor(cursor, int64(time))
+	controller.ruleset.Update(int64(time))
+
+	controller.rulesetTime = time
+}
+
+// SaveReplay saves the movement of the first cursor
+func (controller *GenericController) SaveReplay() string {
+	if controller.recorder == nil {
+		return ""
+	}
+
+	path, err := controller.recorder.Save(controller.bMap, controller.bMap.Diff.Mods, controller.ruleset)
+	if err != nil {
+		log.Println("Failed to save the replay:", err)
+	}
+
+	return path
 }
 
 func (controller *GenericController) GetCursors() []*graphics.Cursor {
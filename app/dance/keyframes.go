@@ -0,0 +1,65 @@
+package dance
+
+import (
+	"github.com/wieku/rplpa"
+	"sort"
+)
+
+// keyFrame is an absolute time state of pressed keys, used by rulesets that don't need cursor movement
+type keyFrame struct {
+	time int64
+	keys uint32
+}
+
+type keyEvent struct {
+	time  int64
+	key   uint32
+	press bool
+}
+
+// buildKeyFrames merges key presses and releases into frames. Events at the same time end up in one frame, so a key shouldn't be released and pressed again at the same time.
+func buildKeyFrames(events []keyEvent) (frames []keyFrame) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time == events[j].time {
+			return !events[i].press && events[j].press
+		}
+
+		return events[i].time < events[j].time
+	})
+
+	keys := uint32(0)
+
+	for _, e := range events {
+		if e.press {
+			keys |= e.key
+		} else {
+			keys &^= e.key
+		}
+
+		if len(frames) > 0 && frames[len(frames)-1].time == e.time {
+			frames[len(frames)-1].keys = keys
+		} else {
+			frames = append(frames, keyFrame{e.time, keys})
+		}
+	}
+
+	return
+}
+
+// convertKeyFrames converts relative replay frames to absolute ones, keyFunc extracts pressed keys from a frame
+func convertKeyFrames(data []*rplpa.ReplayData, keyFunc func(frame *rplpa.ReplayData) uint32) (frames []keyFrame) {
+	time := int64(0)
+
+	for _, frame := range data {
+		// mania seed frame
+		if frame.Time == -12345 {
+			continue
+		}
+
+		time += frame.Time
+
+		frames = append(frames, keyFrame{time, keyFunc(frame)})
+	}
+
+	return
+}
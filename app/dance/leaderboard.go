@@ -0,0 +1,150 @@
+package dance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LeaderboardSource provides replays for knockout mode
+type LeaderboardSource interface {
+	// FetchReplays stores up to limit best replays of the beatmap in dir as .osr files
+	FetchReplays(beatMap *beatmap.BeatMap, dir string, limit int) error
+}
+
+// APILeaderboard downloads replays from osu!api v2 or a server mimicking it
+type APILeaderboard struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewAPILeaderboard(baseURL, token string) *APILeaderboard {
+	return &APILeaderboard{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type apiBeatmap struct {
+	ID int64 `json:"id"`
+}
+
+type apiScore struct {
+	ID     int64 `json:"id"`
+	Replay bool  `json:"replay"`
+	User   struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
+
+type apiScores struct {
+	Scores []apiScore `json:"scores"`
+}
+
+func (source *APILeaderboard) FetchReplays(beatMap *beatmap.BeatMap, dir string, limit int) error {
+	var bMap apiBeatmap
+
+	if err := source.getJSON("/api/v2/beatmaps/lookup?checksum="+url.QueryEscape(beatMap.MD5), &bMap); err != nil {
+		return err
+	}
+
+	if bMap.ID == 0 {
+		return errors.New("beatmap not found on the server")
+	}
+
+	var scores apiScores
+
+	if err := source.getJSON(fmt.Sprintf("/api/v2/beatmaps/%d/scores?mode=osu&limit=%d", bMap.ID, limit), &scores); err != nil {
+		return err
+	}
+
+	downloaded := 0
+
+	for _, score := range scores.Scores {
+		if downloaded >= limit {
+			break
+		}
+
+		if !score.Replay {
+			continue
+		}
+
+		path := filepath.Join(dir, strconv.FormatInt(score.ID, 10)+".osr")
+
+		if _, err := os.Stat(path); err == nil {
+			downloaded++
+			continue
+		}
+
+		log.Println("Downloading replay of:", score.User.Username)
+
+		data, err := source.get(fmt.Sprintf("/api/v2/scores/osu/%d/download", score.ID))
+		if err != nil {
+			log.Println("Failed to download the replay:", err)
+			continue
+		}
+
+		if _, err = rplpa.ParseReplay(data); err != nil {
+			log.Println("Server sent invalid replay:", err)
+			continue
+		}
+
+		if err = ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+
+		downloaded++
+	}
+
+	log.Println(fmt.Sprintf("Downloaded %d online replays", downloaded))
+
+	return nil
+}
+
+func (source *APILeaderboard) getJSON(endpoint string, value interface{}) error {
+	data, err := source.get(endpoint)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+func (source *APILeaderboard) get(endpoint string) ([]byte, error) {
+	req, err := http.NewRequest("GET", source.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if source.token != "" {
+		req.Header.Set("Authorization", "Bearer "+source.token)
+	}
+
+	resp, err := source.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,155 @@
+package dance
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// Max time autoplay holds a normal note
+const maniaAutoHold = 40
+
+// ManiaController plays osu!mania beatmaps using a replay given by -replay or autoplay if it's not specified
+type ManiaController struct {
+	bMap    *beatmap.BeatMap
+	cursors []*graphics.Cursor
+	ruleset *mania.ManiaRuleSet
+
+	frames     []keyFrame
+	frameIndex int
+}
+
+func NewManiaController() *ManiaController {
+	return new(ManiaController)
+}
+
+func (controller *ManiaController) SetBeatMap(beatMap *beatmap.BeatMap) {
+	controller.bMap = beatMap
+}
+
+func (controller *ManiaController) InitCursors() {
+	cursor := graphics.NewCursor()
+	cursor.IsPlayer = true
+	cursor.SetPos(vector.NewVec2f(256, 192))
+
+	controller.cursors = []*graphics.Cursor{cursor}
+
+	mods := controller.bMap.Diff.Mods
+
+	if settings.REPLAY != "" {
+		replay := loadManiaReplay(settings.REPLAY, controller.bMap)
+
+		cursor.Name = replay.Username
+		cursor.ScoreTime = replay.Timestamp
+		mods = difficulty.Modifier(replay.Mods)
+
+		// osu!mania stores pressed columns as a bitmask in X coordinate
+		controller.frames = convertKeyFrames(replay.ReplayData, func(frame *rplpa.ReplayData) uint32 {
+			return uint32(frame.MouseX)
+		})
+	} else {
+		cursor.Name = "danser"
+		cursor.IsAutoplay = true
+	}
+
+	controller.ruleset = mania.NewManiaRuleset(controller.bMap, controller.cursors, []difficulty.Modifier{mods})
+
+	if cursor.IsAutoplay {
+		controller.frames = createManiaAutoplay(controller.ruleset)
+	}
+
+	settings.PLAYERS = 1
+}
+
+func loadManiaReplay(path string, beatMap *beatmap.BeatMap) *rplpa.Replay {
+	log.Println("Loading: ", path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		panic(err)
+	}
+
+	if replay.PlayMode != 3 {
+		panic("Replay is not an osu!mania replay")
+	}
+
+	if !strings.EqualFold(replay.BeatmapMD5, beatMap.MD5) {
+		log.Println("Replay was made on a different version of the beatmap!")
+	}
+
+	if len(replay.ReplayData) == 0 {
+		panic("Replay is missing input data")
+	}
+
+	return replay
+}
+
+func createManiaAutoplay(ruleset *mania.ManiaRuleSet) []keyFrame {
+	columns := make([][]*mania.Note, ruleset.GetKeyCount())
+
+	for _, note := range ruleset.GetNotes() {
+		columns[note.Column] = append(columns[note.Column], note)
+	}
+
+	var events []keyEvent
+
+	for _, notes := range columns {
+		for i, note := range notes {
+			release := note.EndTime
+
+			if !note.Hold {
+				release = note.StartTime + maniaAutoHold
+			}
+
+			// Release earlier so the next note in the same column can be pressed again
+			if i < len(notes)-1 && release >= notes[i+1].StartTime {
+				release = note.StartTime + (notes[i+1].StartTime-note.StartTime)/2
+
+				if note.Hold {
+					release = notes[i+1].StartTime - 1
+				}
+			}
+
+			key := uint32(1) << uint(note.Column)
+
+			events = append(events, keyEvent{note.StartTime, key, true}, keyEvent{release, key, false})
+		}
+	}
+
+	return buildKeyFrames(events)
+}
+
+func (controller *ManiaController) Update(time float64, delta float64) {
+	cursor := controller.cursors[0]
+
+	for controller.frameIndex < len(controller.frames) && controller.frames[controller.frameIndex].time <= int64(time) {
+		frame := controller.frames[controller.frameIndex]
+		controller.ruleset.UpdateKeys(cursor, frame.time, frame.keys)
+
+		controller.frameIndex++
+	}
+
+	controller.ruleset.Update(int64(time))
+
+	cursor.Update(delta)
+}
+
+func (controller *ManiaController) GetCursors() []*graphics.Cursor {
+	return controller.cursors
+}
+
+func (controller *ManiaController) GetRuleset() *mania.ManiaRuleSet {
+	return controller.ruleset
+}
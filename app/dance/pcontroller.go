@@ -5,6 +5,7 @@ import (
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/wieku/danser-go/app/beatmap"
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/dance/input"
 	"github.com/wieku/danser-go/app/dance/movers"
 	"github.com/wieku/danser-go/app/dance/schedulers"
@@ -39,6 +40,9 @@ type PlayerController struct {
 
 	quickRestart     bool
 	quickRestartTime float64
+
+	recorder *ReplayRecorder
+	practice *Practice
 }
 
 func NewPlayerController() Controller {
@@ -57,6 +61,15 @@ func (controller *PlayerController) InitCursors() {
 	controller.window = glfw.GetCurrentContext()
 	controller.ruleset = osu.NewOsuRuleset(controller.bMap, controller.cursors, []difficulty.Modifier{controller.bMap.Diff.Mods})
 
+	if settings.SAVEREPLAY {
+		controller.recorder = NewReplayRecorder(controller.cursors[0])
+	}
+
+	if settings.PRACTICE {
+		controller.practice = NewPractice(controller.bMap, controller.ruleset, controller.cursors[0])
+		input2.RegisterListener(controller.practice.KeyEvent)
+	}
+
 	if !controller.bMap.Diff.CheckModActive(difficulty.Relax) {
 		input2.RegisterListener(controller.KeyEvent)
 	} else {
@@ -150,10 +163,18 @@ func (controller *PlayerController) Update(time float64, delta float64) {
 		if controller.quickRestart && time - controller.quickRestartTime > 500 {
 			controller.quickRestart = false
 
-			utils.QuickRestart()
+			if controller.practice != nil {
+				controller.practice.Restart()
+			} else {
+				utils.QuickRestart()
+			}
 		}
 	}
 
+	if controller.practice != nil {
+		controller.practice.Update(time)
+	}
+
 	controller.counter += time - controller.lastTime
 
 	if controller.counter >= 1000.0/60 {
@@ -163,6 +184,10 @@ func (controller *PlayerController) Update(time float64, delta float64) {
 		controller.cursors[0].IsReplayFrame = false
 	}
 
+	if controller.recorder != nil {
+		controller.recorder.Update(time)
+	}
+
 	controller.ruleset.UpdateClickFor(controller.cursors[0], int64(time))
 	controller.ruleset.UpdateNormalFor(controller.cursors[0], int64(time))
 	controller.ruleset.UpdatePostFor(controller.cursors[0], int64(time))
@@ -173,6 +198,35 @@ func (controller *PlayerController) Update(time float64, delta float64) {
 	controller.cursors[0].Update(delta)
 }
 
+// GetPractice returns practice mode state, nil if -practice is not used
+func (controller *PlayerController) GetPractice() *Practice {
+	return controller.practice
+}
+
+// Rewind resets judgements so objects starting at or after the given time can be played again
+func (controller *PlayerController) Rewind(time float64) {
+	controller.ruleset.Rewind(int64(time))
+
+	if controller.mouseController != nil {
+		var queue []objects.IHitObject
+
+		for _, o := range controller.bMap.HitObjects {
+			if o.GetStartTime() >= time {
+				queue = append(queue, o)
+			}
+		}
+
+		if len(queue) > 0 {
+			controller.mouseController = schedulers.NewGenericScheduler(movers.NewLinearMover)
+			controller.mouseController.Init(queue, controller.bMap.Diff.Mods, controller.cursors[0], spinners.GetMoverCtorByName("circle"), false)
+		}
+	}
+
+	controller.lastTime = time
+	controller.counter = 0
+	controller.quickRestart = false
+}
+
 func (controller *PlayerController) GetRuleset() *osu.OsuRuleSet {
 	return controller.ruleset
 }
@@ -181,6 +235,19 @@ func (controller *PlayerController) GetCursors() []*graphics.Cursor {
 	return controller.cursors
 }
 
+func (controller *PlayerController) SaveReplay() string {
+	if controller.recorder == nil {
+		return ""
+	}
+
+	path, err := controller.recorder.Save(controller.bMap, controller.bMap.Diff.Mods, controller.ruleset)
+	if err != nil {
+		log.Println("Failed to save the replay:", err)
+	}
+
+	return path
+}
+
 func (controller *PlayerController) updateRaw(mousePos vector.Vector2f) {
 	hovered := controller.window.GetAttrib(glfw.Hovered) == 1
 
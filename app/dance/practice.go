@@ -0,0 +1,287 @@
+package dance
+
+import (
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"math"
+	"strings"
+)
+
+const (
+	PracticeMinRate = 0.5
+	PracticeMaxRate = 1.0
+
+	practiceRateStep = 0.05
+
+	// How long to wait after the last judgement of the section before playing it again
+	practiceLoopDelay = 500.0
+)
+
+type PracticeAttempt struct {
+	Accuracy                         float64
+	MaxCombo                         int64
+	Hits300, Hits100, Hits50, Misses int64
+}
+
+// Practice loops a section of the beatmap in -play, rewinds and slows it down on key presses
+type Practice struct {
+	bMap    *beatmap.BeatMap
+	ruleset *osu.OsuRuleSet
+	cursor  *graphics.Cursor
+
+	sectionStart float64
+	sectionEnd   float64
+
+	rate float64
+
+	setStart, setEnd, clear, rewind, slower, faster, restart bool
+
+	rewindPending bool
+	rewindTime    float64
+
+	attempts []PracticeAttempt
+
+	status string
+}
+
+func NewPractice(bMap *beatmap.BeatMap, ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *Practice {
+	practice := &Practice{
+		bMap:    bMap,
+		ruleset: ruleset,
+		cursor:  cursor,
+		rate:    math.Max(PracticeMinRate, math.Min(PracticeMaxRate, settings.PRACTICERATE)),
+	}
+
+	practice.clearSection()
+	practice.initSection()
+	practice.updateStatus()
+
+	return practice
+}
+
+func (practice *Practice) KeyEvent(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action, _ glfw.ModifierKey) {
+	if key == glfw.KeyUnknown || action != glfw.Press {
+		return
+	}
+
+	name := glfw.GetKeyName(key, scancode)
+
+	switch {
+	case strings.EqualFold(name, settings.Input.PracticeRewindKey):
+		practice.rewind = true
+	case strings.EqualFold(name, settings.Input.PracticeStartKey):
+		practice.setStart = true
+	case strings.EqualFold(name, settings.Input.PracticeEndKey):
+		practice.setEnd = true
+	case strings.EqualFold(name, settings.Input.PracticeClearKey):
+		practice.clear = true
+	case strings.EqualFold(name, settings.Input.PracticeSlowerKey):
+		practice.slower = true
+	case strings.EqualFold(name, settings.Input.PracticeFasterKey):
+		practice.faster = true
+	}
+}
+
+// Restart plays the section again from its beginning, the current attempt is not recorded
+func (practice *Practice) Restart() {
+	practice.restart = true
+}
+
+func (practice *Practice) Update(time float64) {
+	if practice.setStart {
+		practice.setStart = false
+
+		if time < practice.bMap.HitObjects[len(practice.bMap.HitObjects)-1].GetStartTime() {
+			practice.sectionStart = time
+			if practice.sectionStart >= practice.sectionEnd {
+				practice.sectionEnd = practice.bMap.HitObjects[len(practice.bMap.HitObjects)-1].GetEndTime()
+			}
+
+			log.Println("Practice: Section starts at", formatPracticeTime(practice.sectionStart))
+
+			practice.updateStatus()
+		} else {
+			log.Println("Practice: Section can't start after the last object")
+		}
+	}
+
+	if practice.setEnd {
+		practice.setEnd = false
+
+		if time > practice.sectionStart {
+			practice.sectionEnd = time
+
+			log.Println("Practice: Section ends at", formatPracticeTime(practice.sectionEnd))
+
+			practice.updateStatus()
+		}
+	}
+
+	if practice.clear {
+		practice.clear = false
+
+		practice.clearSection()
+
+		log.Println("Practice: Section cleared")
+
+		practice.updateStatus()
+	}
+
+	if practice.slower || practice.faster {
+		if practice.slower {
+			practice.rate -= practiceRateStep
+		} else {
+			practice.rate += practiceRateStep
+		}
+
+		practice.slower, practice.faster = false, false
+
+		practice.rate = math.Max(PracticeMinRate, math.Min(PracticeMaxRate, math.Round(practice.rate/practiceRateStep)*practiceRateStep))
+
+		log.Println(fmt.Sprintf("Practice: Playback rate set to %.0f%%", practice.rate*100))
+
+		practice.updateStatus()
+	}
+
+	if practice.rewindPending {
+		return
+	}
+
+	if practice.restart {
+		practice.restart = false
+
+		practice.requestRewind(practice.getLoopStart())
+	} else if practice.rewind {
+		practice.rewind = false
+
+		practice.requestRewind(math.Max(practice.getLoopStart(), time-settings.REWIND*1000))
+	} else if time >= practice.sectionEnd+float64(practice.bMap.Diff.Hit50)+practiceLoopDelay {
+		practice.addAttempt()
+		practice.requestRewind(practice.getLoopStart())
+	}
+}
+
+// PollRewind returns the time the player has to be rewound to, objects starting before it won't be played
+func (practice *Practice) PollRewind() (float64, bool) {
+	if !practice.rewindPending {
+		return 0, false
+	}
+
+	practice.rewindPending = false
+
+	return practice.rewindTime, true
+}
+
+func (practice *Practice) GetRate() float64 {
+	return practice.rate
+}
+
+func (practice *Practice) GetAttempts() []PracticeAttempt {
+	return practice.attempts
+}
+
+// GetStatus returns a short description of the section, playback rate and attempts
+func (practice *Practice) GetStatus() string {
+	return practice.status
+}
+
+func (practice *Practice) requestRewind(time float64) {
+	practice.rewindTime = time
+	practice.rewindPending = true
+}
+
+// getLoopStart returns the time a loop starts at, it's early enough for objects at the section's start to fade in
+func (practice *Practice) getLoopStart() float64 {
+	return math.Max(0, practice.sectionStart-math.Min(1800, practice.bMap.Diff.Preempt))
+}
+
+// initSection sets the section given by -start/-end
+func (practice *Practice) initSection() {
+	if settings.START <= 0.01 && math.IsInf(settings.END, 1) {
+		return
+	}
+
+	start := math.Max(practice.sectionStart, settings.START*1000)
+	end := math.Min(practice.sectionEnd, settings.END*1000)
+
+	if start >= practice.bMap.HitObjects[len(practice.bMap.HitObjects)-1].GetStartTime() || end <= start {
+		log.Println("Practice: -start/-end don't contain any objects, practicing the whole beatmap")
+		return
+	}
+
+	practice.sectionStart = start
+	practice.sectionEnd = end
+
+	if settings.START > 0.01 {
+		// Objects before the section were simulated without input while skipping to it, their judgements have to be cleared
+		practice.requestRewind(practice.getLoopStart())
+	}
+}
+
+func (practice *Practice) clearSection() {
+	practice.sectionStart = practice.bMap.HitObjects[0].GetStartTime()
+	practice.sectionEnd = practice.bMap.HitObjects[len(practice.bMap.HitObjects)-1].GetEndTime()
+}
+
+func (practice *Practice) addAttempt() {
+	accuracy, maxCombo, _, _ := practice.ruleset.GetResults(practice.cursor)
+	n300, n100, n50, misses, _, _ := practice.ruleset.GetHits(practice.cursor)
+
+	attempt := PracticeAttempt{accuracy, maxCombo, n300, n100, n50, misses}
+
+	practice.attempts = append(practice.attempts, attempt)
+
+	best, average := practice.getAccuracyStats()
+
+	log.Println(fmt.Sprintf(
+		"Practice: Attempt %d: %.2f%%, %dx, 300: %d, 100: %d, 50: %d, miss: %d (best: %.2f%%, average: %.2f%%)",
+		len(practice.attempts),
+		attempt.Accuracy,
+		attempt.MaxCombo,
+		attempt.Hits300,
+		attempt.Hits100,
+		attempt.Hits50,
+		attempt.Misses,
+		best,
+		average,
+	))
+
+	practice.updateStatus()
+}
+
+func (practice *Practice) getAccuracyStats() (best, average float64) {
+	for _, a := range practice.attempts {
+		best = math.Max(best, a.Accuracy)
+		average += a.Accuracy
+	}
+
+	if len(practice.attempts) > 0 {
+		average /= float64(len(practice.attempts))
+	}
+
+	return
+}
+
+func (practice *Practice) updateStatus() {
+	status := fmt.Sprintf("Practice %s-%s, %.0f%%", formatPracticeTime(practice.sectionStart), formatPracticeTime(practice.sectionEnd), practice.rate*100)
+
+	if len(practice.attempts) > 0 {
+		best, average := practice.getAccuracyStats()
+		last := practice.attempts[len(practice.attempts)-1]
+
+		status += fmt.Sprintf(", attempt %d, last %.2f%%, best %.2f%%, avg %.2f%%", len(practice.attempts)+1, last.Accuracy, best, average)
+	}
+
+	practice.status = status
+}
+
+func formatPracticeTime(time float64) string {
+	seconds := int64(math.Max(0, time)) / 1000
+
+	return fmt.Sprintf("%02d:%02d.%03d", seconds/60, seconds%60, int64(math.Max(0, time))%1000)
+}
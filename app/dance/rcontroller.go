@@ -19,11 +19,11 @@ import (
 	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
 	//"github.com/wieku/danser-go/app/utils"
+	"github.com/wieku/danser-go/framework/math/curves"
 	"github.com/wieku/danser-go/framework/math/math32"
 	"github.com/wieku/danser-go/framework/math/vector"
 	"io/ioutil"
 	"log"
-	"math"
 	//"net/http"
 	//"net/url"
 	"os"
@@ -51,12 +51,12 @@ type subControl struct {
 	danceController Controller
 	replayIndex     int
 	replayTime      int64
+	replayStart     int64
 	frames          []*rplpa.ReplayData
-	newHandling     bool
 	lastTime        int64
-	oldSpinners     bool
 	relaxController *input.RelaxInputProcessor
 	mouseController schedulers.Scheduler
+	header          *rplpa.Replay
 }
 
 func NewSubControl() *subControl {
@@ -71,10 +71,22 @@ type ReplayController struct {
 	controllers []*subControl
 	ruleset     *osu.OsuRuleSet
 	lastTime    float64
+	sources     []LeaderboardSource
 }
 
 func NewReplayController() Controller {
-	return new(ReplayController)
+	controller := new(ReplayController)
+
+	if settings.Knockout.OnlineReplays {
+		controller.AddLeaderboardSource(NewAPILeaderboard(settings.Knockout.ApiUrl, settings.Knockout.ApiToken))
+	}
+
+	return controller
+}
+
+// AddLeaderboardSource adds a source queried for replays before knockout starts
+func (controller *ReplayController) AddLeaderboardSource(source LeaderboardSource) {
+	controller.sources = append(controller.sources, source)
 }
 
 func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
@@ -127,6 +139,14 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 		Unsorted: true,
 	})
 
+	if settings.REPLAY == "" {
+		for _, source := range controller.sources {
+			if err := source.FetchReplays(beatMap, replayDir, settings.Knockout.OnlineLimit); err != nil {
+				log.Println("Failed to fetch online replays:", err)
+			}
+		}
+	}
+
 	counter := settings.Knockout.MaxPlayers
 
 	excludedMods := osuapi.ParseMods(settings.Knockout.ExcludeMods)
@@ -203,19 +223,16 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 		log.Println("Loading replay for:", replay.Username)
 
 		control := NewSubControl()
+		control.header = replay
 
 		loadFrames(control, replay.ReplayData)
 
 		mxCombo := replay.MaxCombo
 
-		control.newHandling = replay.OsuVersion >= 20190506 // This was when slider scoring was changed, so *I think* replay handling as well: https://osu.ppy.sh/home/changelog/cuttingedge/20190506
-		control.oldSpinners = replay.OsuVersion < 20190510  // This was when spinner scoring was changed: https://osu.ppy.sh/home/changelog/cuttingedge/20190510.2
-
 		controller.replays = append(controller.replays, RpData{replay.Username + string(rune(unicode.MaxRune-i)), difficulty.Modifier(replay.Mods & displayedMods).String(), difficulty.Modifier(replay.Mods), 100, 0, int64(mxCombo), osu.NONE, replay.ScoreID, replay.Timestamp})
 		controller.controllers = append(controller.controllers, control)
 
 		log.Println("Expected score:", replay.Score)
-		log.Println("Expected pp:", math.NaN())
 		log.Println("Replay loaded!")
 
 		counter--
@@ -277,12 +294,13 @@ func (controller *ReplayController) InitCursors() {
 			cursor.Name = controller.replays[i].Name
 			cursor.ScoreID = controller.replays[i].scoreID
 			cursor.ScoreTime = controller.replays[i].ScoreTime
-			cursor.OldSpinnerScoring = controller.controllers[i].oldSpinners
+			cursor.ReplayVersion = controller.controllers[i].header.OsuVersion
 
 			cursor.SetPos(vector.NewVec2f(c.frames[0].MouseX, c.frames[0].MouseY))
 			cursor.Update(0)
 
 			c.replayTime += c.frames[0].Time
+			c.replayStart = c.replayTime
 			c.frames = c.frames[1:]
 
 			controller.cursors = append(controller.cursors, cursor)
@@ -309,6 +327,41 @@ func (controller *ReplayController) InitCursors() {
 	}
 }
 
+// Reset judges all replays again from the beginning, the beatmap has to be rewound separately
+func (controller *ReplayController) Reset() {
+	controller.ruleset.Rewind(int64(controller.bMap.HitObjects[0].GetStartTime()))
+
+	for i, c := range controller.controllers {
+		c.lastTime = 0
+
+		if c.danceController != nil {
+			c.danceController.(Resettable).Reset()
+			continue
+		}
+
+		c.replayIndex = 0
+		c.replayTime = c.replayStart
+
+		cursor := controller.cursors[i]
+
+		cursor.LeftKey, cursor.RightKey = false, false
+		cursor.LeftMouse, cursor.RightMouse = false, false
+		cursor.LeftButton, cursor.RightButton = false, false
+		cursor.SmokeKey = false
+
+		if c.relaxController != nil {
+			c.relaxController = input.NewRelaxInputProcessor(controller.ruleset, cursor)
+		}
+
+		if c.mouseController != nil {
+			c.mouseController = schedulers.NewGenericScheduler(movers.NewLinearMover)
+			c.mouseController.Init(controller.bMap.GetObjectsCopy(), controller.replays[i].ModsV, cursor, spinners.GetMoverCtorByName("circle"), false)
+		}
+	}
+
+	controller.lastTime = -200
+}
+
 func (controller *ReplayController) Update(time float64, delta float64) {
 	numSkipped := int(time-controller.lastTime) - 1
 
@@ -395,8 +448,8 @@ func (controller *ReplayController) updateMain(nTime float64) {
 					controller.ruleset.UpdateClickFor(controller.cursors[i], c.replayTime)
 					controller.ruleset.UpdateNormalFor(controller.cursors[i], c.replayTime)
 
-					// New replays (after 20190506) scores object ends only on replay frame
-					if c.newHandling || c.replayIndex == len(c.frames)-1 {
+					// New replays score object ends only on replay frame
+					if controller.ruleset.IsNewInputHandling(controller.cursors[i]) || c.replayIndex == len(c.frames)-1 {
 						controller.ruleset.UpdatePostFor(controller.cursors[i], c.replayTime)
 					} else {
 						localIndex := bmath.ClampI(c.replayIndex+1, 0, len(c.frames)-1)
@@ -415,16 +468,7 @@ func (controller *ReplayController) updateMain(nTime float64) {
 
 				if !wasUpdated {
 					if !isAutopilot {
-						localIndex := bmath.ClampI(c.replayIndex, 0, len(c.frames)-1)
-
-						progress := math32.Min(float32(nTime-float64(c.replayTime)), float32(c.frames[localIndex].Time)) / float32(c.frames[localIndex].Time)
-
-						prevIndex := bmath.MaxI(0, localIndex-1)
-
-						mX := (c.frames[localIndex].MouseX-c.frames[prevIndex].MouseX)*progress + c.frames[prevIndex].MouseX
-						mY := (c.frames[localIndex].MouseY-c.frames[prevIndex].MouseY)*progress + c.frames[prevIndex].MouseY
-
-						controller.cursors[i].SetPos(vector.NewVec2f(mX, mY))
+						c.interpolate(controller.cursors[i], nTime)
 					}
 
 					controller.cursors[i].IsReplayFrame = false
@@ -459,6 +503,21 @@ func (controller *ReplayController) GetReplays() []RpData {
 	return controller.replays
 }
 
+// GetPlayerName returns i-th player's name without the rune used to keep names unique
+func (controller *ReplayController) GetPlayerName(i int) string {
+	runes := []rune(controller.replays[i].Name)
+	if len(runes) > 0 && runes[len(runes)-1] > unicode.MaxRune-rune(len(controller.replays)) {
+		return string(runes[:len(runes)-1])
+	}
+
+	return controller.replays[i].Name
+}
+
+// GetReplayHeader returns parsed replay of i-th player, nil if that player is danser
+func (controller *ReplayController) GetReplayHeader(i int) *rplpa.Replay {
+	return controller.controllers[i].header
+}
+
 func (controller *ReplayController) GetRuleset() *osu.OsuRuleSet {
 	return controller.ruleset
 }
@@ -481,3 +540,31 @@ func (controller *ReplayController) GetClick(player, key int) bool {
 
 	return false
 }
+
+// interpolate moves drawn cursor between replay frames, ruleset still sees the position of the last frame
+func (c *subControl) interpolate(cursor *graphics.Cursor, nTime float64) {
+	mode := strings.ToLower(settings.Cursor.ReplayInterpolation)
+	if mode == "none" {
+		return
+	}
+
+	localIndex := bmath.ClampI(c.replayIndex, 0, len(c.frames)-1)
+
+	progress := math32.Min(float32(nTime-float64(c.replayTime)), float32(c.frames[localIndex].Time)) / float32(c.frames[localIndex].Time)
+
+	prevIndex := bmath.MaxI(0, localIndex-1)
+
+	framePos := func(index int) vector.Vector2f {
+		frame := c.frames[bmath.ClampI(index, 0, len(c.frames)-1)]
+		return vector.NewVec2f(frame.MouseX, frame.MouseY)
+	}
+
+	if mode == "catmull" {
+		catmull := curves.NewCatmull([]vector.Vector2f{framePos(prevIndex - 1), framePos(prevIndex), framePos(localIndex), framePos(localIndex + 1)})
+		cursor.SetDisplayPos(catmull.PointAt(progress))
+
+		return
+	}
+
+	cursor.SetDisplayPos(framePos(prevIndex).Lerp(framePos(localIndex), progress))
+}
@@ -0,0 +1,297 @@
+package dance
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/itchio/lzma"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	exportedReplays = "exported"
+
+	// Has to be newer than 20190510 so danser uses new slider and spinner handling while loading it back
+	replayOsuVersion = 20210520
+
+	replayFrameInterval = 16
+)
+
+type ReplayRecorder struct {
+	cursor *graphics.Cursor
+
+	frames []*rplpa.ReplayData
+
+	lastTime  int64
+	lastFrame int64
+	lastKeys  int
+	started   bool
+
+	mutex sync.Mutex
+}
+
+func NewReplayRecorder(cursor *graphics.Cursor) *ReplayRecorder {
+	return &ReplayRecorder{cursor: cursor}
+}
+
+// Update records cursor state at the given time. Frames are written at ~60Hz or whenever input changes, it returns true if a frame was written.
+func (recorder *ReplayRecorder) Update(time float64) bool {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	iTime := int64(time)
+
+	keys := recorder.getKeys()
+
+	if recorder.started && (iTime <= recorder.lastTime || (iTime-recorder.lastFrame < replayFrameInterval && keys == recorder.lastKeys)) {
+		return false
+	}
+
+	position := recorder.cursor.RawPosition
+
+	if !recorder.started {
+		// osu! expects two "empty" frames at the beginning, second one holds the starting time
+		recorder.frames = append(recorder.frames,
+			newFrame(0, 256, -500, 0),
+			newFrame(iTime, 256, -500, 0),
+		)
+
+		recorder.lastFrame = iTime
+		recorder.started = true
+	}
+
+	recorder.frames = append(recorder.frames, newFrame(iTime-recorder.lastFrame, position.X, position.Y, keys))
+
+	recorder.lastTime = iTime
+	recorder.lastFrame = iTime
+	recorder.lastKeys = keys
+
+	return true
+}
+
+func (recorder *ReplayRecorder) getKeys() (keys int) {
+	if recorder.cursor.LeftButton {
+		keys |= rplpa.LEFTCLICK
+
+		if recorder.cursor.LeftKey {
+			keys |= rplpa.KEY1
+		}
+	}
+
+	if recorder.cursor.RightButton {
+		keys |= rplpa.RIGHTCLICK
+
+		if recorder.cursor.RightKey {
+			keys |= rplpa.KEY2
+		}
+	}
+
+	if recorder.cursor.SmokeKey {
+		keys |= rplpa.SMOKE
+	}
+
+	return
+}
+
+// Save writes recorded frames as an .osr file in replays/exported directory with score and hit counts judged by the ruleset
+func (recorder *ReplayRecorder) Save(beatMap *beatmap.BeatMap, mods difficulty.Modifier, ruleset *osu.OsuRuleSet) (string, error) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	replay := &rplpa.Replay{
+		PlayMode:   rplpa.OSU,
+		OsuVersion: replayOsuVersion,
+		BeatmapMD5: beatMap.MD5,
+		Username:   recorder.cursor.Name,
+		Mods:       uint32(mods),
+		Timestamp:  time.Now(),
+		ReplayData: recorder.frames,
+	}
+
+	if replay.Username == "" {
+		replay.Username = settings.Knockout.DanserName
+	}
+
+	_, maxCombo, score, _ := ruleset.GetResults(recorder.cursor)
+	n300, n100, n50, nMiss, nGeki, nKatu := ruleset.GetHits(recorder.cursor)
+
+	replay.Count300 = uint16(n300)
+	replay.Count100 = uint16(n100)
+	replay.Count50 = uint16(n50)
+	replay.CountMiss = uint16(nMiss)
+	replay.CountGeki = uint16(nGeki)
+	replay.CountKatu = uint16(nKatu)
+	replay.Score = int32(score)
+	replay.MaxCombo = uint16(maxCombo)
+	replay.Fullcombo = nMiss == 0 && n300+n100+n50 > 0 && ruleset.IsPerfect(recorder.cursor)
+
+	data, err := encodeReplay(replay)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(replaysMaster, exportedReplays)
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "danser_"+time.Now().Format("2006-01-02_15-04-05")+".osr")
+
+	if err = ioutil.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+
+	log.Println("Replay saved to:", path)
+
+	return path, nil
+}
+
+func newFrame(delta int64, x, y float32, keys int) *rplpa.ReplayData {
+	return &rplpa.ReplayData{
+		Time:   delta,
+		MouseX: x,
+		MouseY: y,
+		KeyPressed: &rplpa.KeyPressed{
+			LeftClick:  keys&rplpa.LEFTCLICK > 0,
+			RightClick: keys&rplpa.RIGHTCLICK > 0,
+			Key1:       keys&rplpa.KEY1 > 0,
+			Key2:       keys&rplpa.KEY2 > 0,
+			Smoke:      keys&rplpa.SMOKE > 0,
+		},
+	}
+}
+
+func encodeReplay(replay *rplpa.Replay) ([]byte, error) {
+	compressed, err := compressFrames(replay.ReplayData)
+	if err != nil {
+		return nil, err
+	}
+
+	replayHash := md5.Sum(append([]byte(replay.BeatmapMD5+replay.Username), compressed...))
+
+	buf := new(bytes.Buffer)
+
+	write := func(value interface{}) {
+		_ = binary.Write(buf, binary.LittleEndian, value)
+	}
+
+	write(replay.PlayMode)
+	write(replay.OsuVersion)
+	writeString(buf, replay.BeatmapMD5)
+	writeString(buf, replay.Username)
+	writeString(buf, hex.EncodeToString(replayHash[:]))
+	write(replay.Count300)
+	write(replay.Count100)
+	write(replay.Count50)
+	write(replay.CountGeki)
+	write(replay.CountKatu)
+	write(replay.CountMiss)
+	write(replay.Score)
+	write(replay.MaxCombo)
+	write(replay.Fullcombo)
+	write(replay.Mods)
+	writeString(buf, "")
+	write(toTicks(replay.Timestamp))
+	write(int32(len(compressed)))
+	buf.Write(compressed)
+	write(replay.ScoreID)
+
+	return buf.Bytes(), nil
+}
+
+func compressFrames(frames []*rplpa.ReplayData) ([]byte, error) {
+	var builder strings.Builder
+
+	for _, frame := range frames {
+		keys := 0
+
+		if frame.KeyPressed != nil {
+			if frame.KeyPressed.LeftClick {
+				keys |= rplpa.LEFTCLICK
+			}
+
+			if frame.KeyPressed.RightClick {
+				keys |= rplpa.RIGHTCLICK
+			}
+
+			if frame.KeyPressed.Key1 {
+				keys |= rplpa.KEY1
+			}
+
+			if frame.KeyPressed.Key2 {
+				keys |= rplpa.KEY2
+			}
+
+			if frame.KeyPressed.Smoke {
+				keys |= rplpa.SMOKE
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf("%d|%g|%g|%d,", frame.Time, frame.MouseX, frame.MouseY, keys))
+	}
+
+	raw := []byte(builder.String())
+
+	buf := new(bytes.Buffer)
+
+	writer := lzma.NewWriterSize(buf, int64(len(raw)))
+
+	if _, err := writer.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeString writes an osu! string: 0x0b marker, ULEB128 length and UTF-8 bytes
+func writeString(buf *bytes.Buffer, value string) {
+	if value == "" {
+		buf.WriteByte(0)
+		return
+	}
+
+	buf.WriteByte(0x0b)
+
+	length := uint(len(value))
+
+	for {
+		b := byte(length & 0x7f)
+		length >>= 7
+
+		if length != 0 {
+			b |= 0x80
+		}
+
+		buf.WriteByte(b)
+
+		if length == 0 {
+			break
+		}
+	}
+
+	buf.WriteString(value)
+}
+
+// toTicks converts time to .NET DateTime ticks
+func toTicks(t time.Time) int64 {
+	return (t.Unix()+62135596800)*10000000 + int64(t.Nanosecond()/100)
+}
@@ -0,0 +1,223 @@
+package dance
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+	"io/ioutil"
+	"log"
+	"math"
+	"strings"
+)
+
+// Max time autoplay holds a drum part
+const taikoAutoHold = 40
+
+// TaikoController plays osu!taiko beatmaps using a replay given by -replay or autoplay if it's not specified
+type TaikoController struct {
+	bMap    *beatmap.BeatMap
+	cursors []*graphics.Cursor
+	ruleset *taiko.TaikoRuleSet
+
+	frames     []keyFrame
+	frameIndex int
+}
+
+func NewTaikoController() *TaikoController {
+	return new(TaikoController)
+}
+
+func (controller *TaikoController) SetBeatMap(beatMap *beatmap.BeatMap) {
+	controller.bMap = beatMap
+}
+
+func (controller *TaikoController) InitCursors() {
+	cursor := graphics.NewCursor()
+	cursor.IsPlayer = true
+	cursor.SetPos(vector.NewVec2f(256, 192))
+
+	controller.cursors = []*graphics.Cursor{cursor}
+
+	mods := controller.bMap.Diff.Mods
+
+	if settings.REPLAY != "" {
+		replay := loadTaikoReplay(settings.REPLAY, controller.bMap)
+
+		cursor.Name = replay.Username
+		cursor.ScoreTime = replay.Timestamp
+		mods = difficulty.Modifier(replay.Mods)
+
+		controller.frames = convertKeyFrames(replay.ReplayData, taikoKeys)
+	} else {
+		cursor.Name = "danser"
+		cursor.IsAutoplay = true
+	}
+
+	controller.ruleset = taiko.NewTaikoRuleset(controller.bMap, controller.cursors, []difficulty.Modifier{mods})
+
+	if cursor.IsAutoplay {
+		controller.frames = createTaikoAutoplay(controller.ruleset)
+	}
+
+	settings.PLAYERS = 1
+}
+
+// taikoKeys maps mouse buttons and keys of a replay frame to drum parts
+func taikoKeys(frame *rplpa.ReplayData) (keys uint32) {
+	if frame.KeyPressed.LeftClick {
+		keys |= taiko.DonLeft
+	}
+
+	if frame.KeyPressed.RightClick {
+		keys |= taiko.KatLeft
+	}
+
+	if frame.KeyPressed.Key1 {
+		keys |= taiko.DonRight
+	}
+
+	if frame.KeyPressed.Key2 {
+		keys |= taiko.KatRight
+	}
+
+	return
+}
+
+func loadTaikoReplay(path string, beatMap *beatmap.BeatMap) *rplpa.Replay {
+	log.Println("Loading: ", path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		panic(err)
+	}
+
+	if replay.PlayMode != 1 {
+		panic("Replay is not an osu!taiko replay")
+	}
+
+	if !strings.EqualFold(replay.BeatmapMD5, beatMap.MD5) {
+		log.Println("Replay was made on a different version of the beatmap!")
+	}
+
+	if len(replay.ReplayData) == 0 {
+		panic("Replay is missing input data")
+	}
+
+	return replay
+}
+
+func createTaikoAutoplay(ruleset *taiko.TaikoRuleSet) []keyFrame {
+	var events []keyEvent
+
+	// Hands alternate between hits, lastRelease keeps track of when each drum part is free again
+	lastRelease := make(map[uint32]int64)
+	rightHand := false
+
+	press := func(time int64, key uint32, nextTime int64) {
+		if last, ok := lastRelease[key]; ok && last >= time {
+			return
+		}
+
+		release := time + bmath.ClampI64((nextTime-time)/2, 1, taikoAutoHold)
+
+		events = append(events, keyEvent{time, key, true}, keyEvent{release, key, false})
+
+		lastRelease[key] = release
+	}
+
+	hand := func(don bool) uint32 {
+		rightHand = !rightHand
+
+		switch {
+		case don && rightHand:
+			return taiko.DonRight
+		case don:
+			return taiko.DonLeft
+		case rightHand:
+			return taiko.KatRight
+		}
+
+		return taiko.KatLeft
+	}
+
+	objects := ruleset.GetObjects()
+
+	for i, object := range objects {
+		nextTime := int64(math.MaxInt32)
+		if i < len(objects)-1 {
+			nextTime = objects[i+1].StartTime
+		}
+
+		switch object.Type {
+		case taiko.Don, taiko.Kat:
+			don := object.Type == taiko.Don
+
+			if object.Big {
+				left, right := taiko.DonLeft, taiko.DonRight
+				if !don {
+					left, right = taiko.KatLeft, taiko.KatRight
+				}
+
+				press(object.StartTime, left, nextTime)
+				press(object.StartTime, right, nextTime)
+
+				continue
+			}
+
+			press(object.StartTime, hand(don), nextTime)
+		case taiko.DrumRoll:
+			for j, tick := range object.Ticks {
+				tickNext := nextTime
+				if j < len(object.Ticks)-1 {
+					tickNext = object.Ticks[j+1]
+				}
+
+				press(tick, hand(true), tickNext)
+			}
+		case taiko.Swell:
+			hits := object.RequiredHits
+			spacing := float64(object.EndTime-object.StartTime) / float64(hits)
+
+			for j := int64(0); j < hits; j++ {
+				time := object.StartTime + int64(float64(j)*spacing)
+
+				press(time, hand(j%2 == 0), object.StartTime+int64(float64(j+1)*spacing))
+			}
+		}
+	}
+
+	return buildKeyFrames(events)
+}
+
+func (controller *TaikoController) Update(time float64, delta float64) {
+	cursor := controller.cursors[0]
+
+	for controller.frameIndex < len(controller.frames) && controller.frames[controller.frameIndex].time <= int64(time) {
+		frame := controller.frames[controller.frameIndex]
+		controller.ruleset.UpdateKeys(cursor, frame.time, frame.keys)
+
+		controller.frameIndex++
+	}
+
+	controller.ruleset.Update(int64(time))
+
+	cursor.Update(delta)
+}
+
+func (controller *TaikoController) GetCursors() []*graphics.Cursor {
+	return controller.cursors
+}
+
+func (controller *TaikoController) GetRuleset() *taiko.TaikoRuleSet {
+	return controller.ruleset
+}
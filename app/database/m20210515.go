@@ -0,0 +1,29 @@
+package database
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+)
+
+type M20210515 struct {}
+
+func (m *M20210515) RequiredSections() []string {
+	return nil
+}
+
+func (m *M20210515) FieldsToMigrate() []string {
+	return nil
+}
+
+func (m *M20210515) GetValues(_ *beatmap.BeatMap) []interface{} {
+	return nil
+}
+
+func (m *M20210515) Date() int {
+	return 20210515
+}
+
+func (m *M20210515) GetMigrationStmts() string {
+	return `
+		CREATE TABLE IF NOT EXISTS scores (md5 TEXT, name TEXT, mods TEXT, score INTEGER, accuracy REAL, maxCombo INTEGER, count300 INTEGER, count100 INTEGER, count50 INTEGER, countMiss INTEGER, countGeki INTEGER, countKatu INTEGER, grade TEXT, pp REAL, timestamp INTEGER, replay TEXT);
+		CREATE INDEX IF NOT EXISTS scoresIdx ON scores (md5);`
+}
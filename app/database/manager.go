@@ -21,7 +21,7 @@ import (
 
 var dbFile *sql.DB
 
-const databaseVersion = 20210423
+const databaseVersion = 20210515
 
 var currentPreVersion = databaseVersion
 var currentSchemaPreVersion = databaseVersion
@@ -59,6 +59,7 @@ func Init() error {
 		&M20210104{},
 		&M20210326{},
 		&M20210423{},
+		&M20210515{},
 	}
 
 	dbFile, err = sql.Open("sqlite3", "danser.db")
@@ -70,6 +71,8 @@ func Init() error {
 		CREATE TABLE IF NOT EXISTS beatmaps (dir TEXT, file TEXT, lastModified INTEGER, title TEXT, titleUnicode TEXT, artist TEXT, artistUnicode TEXT, creator TEXT, version TEXT, source TEXT, tags TEXT, cs REAL, ar REAL, sliderMultiplier REAL, sliderTickRate REAL, audioFile TEXT, previewTime INTEGER, sampleSet INTEGER, stackLeniency REAL, mode INTEGER, bg TEXT, md5 TEXT, dateAdded INTEGER, playCount INTEGER, lastPlayed INTEGER, hpdrain REAL, od REAL, stars REAL DEFAULT -1, bpmMin REAL, bpmMax REAL, circles INTEGER, sliders INTEGER, spinners INTEGER, endTime INTEGER, setID INTEGER, mapID INTEGER);
 		CREATE INDEX IF NOT EXISTS idx ON beatmaps (dir, file);
 		CREATE TABLE IF NOT EXISTS info (key TEXT NOT NULL UNIQUE, value TEXT);
+		CREATE TABLE IF NOT EXISTS scores (md5 TEXT, name TEXT, mods TEXT, score INTEGER, accuracy REAL, maxCombo INTEGER, count300 INTEGER, count100 INTEGER, count50 INTEGER, countMiss INTEGER, countGeki INTEGER, countKatu INTEGER, grade TEXT, pp REAL, timestamp INTEGER, replay TEXT);
+		CREATE INDEX IF NOT EXISTS scoresIdx ON scores (md5);
 	`)
 
 	if err != nil {
@@ -157,17 +160,17 @@ func LoadBeatmaps(skipDatabaseCheck bool) []*beatmap.BeatMap {
 
 	allMaps := loadBeatmapsFromDatabase()
 
-	stdMaps := make([]*beatmap.BeatMap, 0, len(allMaps) / 2)
+	supportedMaps := make([]*beatmap.BeatMap, 0, len(allMaps) / 2)
 
 	for _, b := range allMaps {
-		if b.Mode == 0 {
-			stdMaps = append(stdMaps, b)
+		if b.Mode >= 0 && b.Mode <= 3 {
+			supportedMaps = append(supportedMaps, b)
 		}
 	}
 
-	log.Println("DatabaseManager: Loaded", len(stdMaps), "total.")
+	log.Println("DatabaseManager: Loaded", len(supportedMaps), "total.")
 
-	return stdMaps
+	return supportedMaps
 }
 
 func unpackMaps() {
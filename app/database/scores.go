@@ -0,0 +1,96 @@
+package database
+
+import (
+	"strings"
+	"time"
+)
+
+type Score struct {
+	MD5        string
+	Name       string
+	Mods       string
+	Score      int64
+	Accuracy   float64
+	MaxCombo   int64
+	Count300   int64
+	Count100   int64
+	Count50    int64
+	CountMiss  int64
+	CountGeki  int64
+	CountKatu  int64
+	Grade      string
+	PP         float64
+	Time       time.Time
+	ReplayPath string
+}
+
+func InsertScore(score *Score) error {
+	_, err := dbFile.Exec(
+		"INSERT INTO scores VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		strings.ToLower(score.MD5),
+		score.Name,
+		score.Mods,
+		score.Score,
+		score.Accuracy,
+		score.MaxCombo,
+		score.Count300,
+		score.Count100,
+		score.Count50,
+		score.CountMiss,
+		score.CountGeki,
+		score.CountKatu,
+		score.Grade,
+		score.PP,
+		score.Time.UnixNano()/1000000,
+		score.ReplayPath,
+	)
+
+	return err
+}
+
+// GetTopScores returns best local scores for a beatmap, sorted by score
+func GetTopScores(md5 string, limit int) ([]*Score, error) {
+	res, err := dbFile.Query("SELECT * FROM scores WHERE md5 = ? ORDER BY score DESC, timestamp ASC LIMIT ?", strings.ToLower(md5), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	scores := make([]*Score, 0)
+
+	for res.Next() {
+		score := new(Score)
+
+		var timestamp int64
+
+		err = res.Scan(
+			&score.MD5,
+			&score.Name,
+			&score.Mods,
+			&score.Score,
+			&score.Accuracy,
+			&score.MaxCombo,
+			&score.Count300,
+			&score.Count100,
+			&score.Count50,
+			&score.CountMiss,
+			&score.CountGeki,
+			&score.CountKatu,
+			&score.Grade,
+			&score.PP,
+			&timestamp,
+			&score.ReplayPath,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		score.Time = time.Unix(0, timestamp*1000000)
+
+		scores = append(scores, score)
+	}
+
+	return scores, res.Err()
+}
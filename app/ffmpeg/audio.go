@@ -0,0 +1,117 @@
+package ffmpeg
+
+import (
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/bass"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Audio chunks are pushed once per frame, it's enough to hold a few seconds of them
+const audioBuffers = 1024
+
+var singlePass bool
+
+var audioPipe *os.File
+var audioQueue chan []byte
+var audioSync *sync.WaitGroup
+
+// IsSinglePass returns true if audio is muxed by the ffmpeg process encoding video.
+// Otherwise it has to be saved with bass.SaveToFile and merged by Combine after recording.
+func IsSinglePass() bool {
+	return singlePass
+}
+
+func checkSinglePass() bool {
+	// Audio is saved separately when the encoder is bypassed, segments get it from the last one
+	if !settings.Recording.SinglePass || !isVideoOutput() || segment != nil {
+		return false
+	}
+
+	// Extra pipes can't be passed to child processes on Windows
+	if runtime.GOOS == "windows" {
+		log.Println("Single pass muxing is not supported on this platform, audio will be combined after recording")
+		return false
+	}
+
+	return true
+}
+
+// audioInput returns ffmpeg options reading offscreen BASS mix from the first extra pipe
+func audioInput() []string {
+	return []string{
+		"-f", "f32le",
+		"-ar", "48000",
+		"-ac", "2",
+		"-thread_queue_size", "1024",
+		"-i", "pipe:3",
+	}
+}
+
+func audioOutput() []string {
+	options := []string{
+		"-map", "0:v",
+		"-map", "1:a",
+	}
+
+	filters := strings.TrimSpace(settings.Recording.AudioFilters)
+	if len(filters) > 0 {
+		options = append(options, "-af", filters)
+	}
+
+	return append(options,
+		"-c:a", settings.Recording.AudioCodec,
+		"-ab", settings.Recording.AudioBitrate,
+	)
+}
+
+// startAudio passes audio pipe to ffmpeg, it has to be called before the process is started
+func startAudio() {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		panic(err)
+	}
+
+	cmd.ExtraFiles = []*os.File{reader}
+	audioPipe = writer
+
+	bass.StartStream()
+
+	audioQueue = make(chan []byte, audioBuffers)
+
+	audioSync = &sync.WaitGroup{}
+	audioSync.Add(1)
+
+	go func() {
+		for data := range audioQueue {
+			if _, err := audioPipe.Write(data); err != nil {
+				panic(err)
+			}
+		}
+
+		audioSync.Done()
+	}()
+}
+
+// pushAudio sends audio mixed up to current time, it's called before each frame so audio is never behind video
+func pushAudio() {
+	if data := bass.ReadStream(bass.GlobalTimeMs); len(data) > 0 {
+		audioQueue <- data
+	}
+}
+
+func stopAudio() {
+	pushAudio()
+
+	close(audioQueue)
+	audioSync.Wait()
+
+	audioPipe.Close()
+
+	bass.StopStream()
+
+	log.Println("Audio pipe closed.")
+}
@@ -1,26 +1,35 @@
 package ffmpeg
 
 import (
+	"errors"
 	"github.com/wieku/danser-go/app/settings"
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
-	"time"
 )
 
-func Combine(output string) {
-	if strings.TrimSpace(output) == "" {
-		output = "danser_" + time.Now().Format("2006-01-02_15-04-05")
+// Combine muxes recorded video and audio into the output file.
+// The file is written under a temporary name first so a cut off render never looks finished.
+// Cancelled recordings are saved with _partial suffix.
+// In image and intermediate modes audio is saved next to the output with a timing file instead.
+func Combine() error {
+	if !isVideoOutput() {
+		return finishSequence()
 	}
 
-	options := []string{
-		"-y",
-		"-i", filepath.Join(settings.Recording.OutputDir, filename+"."+settings.Recording.Container),
-		"-i", filepath.Join(settings.Recording.OutputDir, filename+".wav"),
-		"-c:v", "copy",
-	}
+	return combine("-i", getPath(filename, GetContainer()))
+}
+
+// combine muxes audio into video read with given input options, it's also used to join recorded segments
+func combine(videoInput ...string) error {
+	setStage(StageMuxing)
+
+	options := append([]string{"-y"}, videoInput...)
+	options = append(options, "-i", getPath(filename, "wav"))
+
+	options = append(options, metadataInput(2)...)
+	options = append(options, "-c:v", "copy")
 
 	filters := strings.TrimSpace(settings.Recording.AudioFilters)
 	if len(filters) > 0 {
@@ -32,11 +41,23 @@ func Combine(output string) {
 		"-ab", settings.Recording.AudioBitrate,
 		)
 
+	// Music was mixed in full, cut it to the recorded part
+	if IsCancelled() {
+		options = append(options, "-shortest")
+	}
+
+	movFlags := metadataMovFlags()
 	if settings.Recording.Container == "mp4" {
-		options = append(options, "-movflags", "+faststart")
+		movFlags = "+faststart" + movFlags
 	}
 
-	options = append(options, filepath.Join(settings.Recording.OutputDir, output+"."+settings.Recording.Container))
+	if movFlags != "" {
+		options = append(options, "-movflags", movFlags)
+	}
+
+	partPath := getPath(getOutputName()+".part", GetContainer())
+
+	options = append(options, partPath)
 
 	log.Println("Starting composing audio and video into one file...")
 	log.Println("Running ffmpeg with options:", options)
@@ -44,20 +65,63 @@ func Combine(output string) {
 	cmd2.Stdout = os.Stdout
 	cmd2.Stderr = os.Stderr
 
-	if err := cmd2.Start(); err != nil {
+	err := cmd2.Start()
+	if err != nil {
 		log.Println("Failed to start ffmpeg:", err)
-	} else {
-		if err = cmd2.Wait(); err != nil {
-			log.Println("ffmpeg finished abruptly! Please check if you have enough storage or audio bitrate is entered correctly.")
-		} else {
-			log.Println("Finished!")
-		}
+	} else if err = cmd2.Wait(); err != nil {
+		log.Println("ffmpeg finished abruptly! Please check if you have enough storage or audio bitrate is entered correctly.")
+		err = errors.New("ffmpeg failed to combine audio and video: " + err.Error())
 	}
 
+	err = finishOutput(partPath, err)
+
 	log.Println("Cleaning up intermediate files...")
 
-	_ = os.Remove(filepath.Join(settings.Recording.OutputDir, filename+"."+settings.Recording.Container))
-	_ = os.Remove(filepath.Join(settings.Recording.OutputDir, filename+".wav"))
+	_ = os.Remove(getPath(filename, GetContainer()))
+	_ = os.Remove(getPath(filename, "wav"))
+	removeMetadata()
 
 	log.Println("Finished.")
+
+	return err
+}
+
+func getOutputName() string {
+	if IsCancelled() {
+		return outputName + "_partial"
+	}
+
+	return outputName
+}
+
+// finishOutput moves finished video from its temporary path to the output one, or removes it if ffmpeg failed
+func finishOutput(tempPath string, err error) error {
+	outPath := GetOutputPath(getOutputName())
+
+	if err != nil {
+		// Image sequences are saved to a directory
+		_ = os.RemoveAll(tempPath)
+
+		setStage(StageFailed)
+
+		return err
+	}
+
+	if err = os.Rename(tempPath, outPath); err != nil {
+		// Output is complete, it's only left under its temporary name
+		log.Println("Failed to rename output file:", err)
+		log.Println("Output was left at:", tempPath)
+
+		setOutput(tempPath)
+		setStage(StageFailed)
+
+		return err
+	}
+
+	log.Println("Video saved to:", outPath)
+
+	setOutput(outPath)
+	setStage(StageFinished)
+
+	return nil
 }
@@ -8,20 +8,23 @@ import (
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/framework/graphics/effects"
 	"io"
+	"io/ioutil"
 	"log"
 	"math/rand"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 const MaxBuffers = 10
 
 var filename string
+var outputName string
 
 var cmd *exec.Cmd
 var pipe io.WriteCloser
@@ -30,6 +33,7 @@ var queue chan func()
 var endSync *sync.WaitGroup
 
 var w, h int
+var bytesPerPixel = 3
 
 type PBO struct {
 	handle     uint32
@@ -43,15 +47,28 @@ func createPBO() *PBO {
 	pbo := new(PBO)
 
 	gl.CreateBuffers(1, &pbo.handle)
-	gl.NamedBufferStorage(pbo.handle, w*h*3, gl.Ptr(nil), gl.MAP_PERSISTENT_BIT|gl.MAP_READ_BIT)
+	size := w * h * bytesPerPixel
 
-	pbo.memPointer = gl.MapNamedBufferRange(pbo.handle, 0, w*h*3, gl.MAP_PERSISTENT_BIT|gl.MAP_READ_BIT)
+	gl.NamedBufferStorage(pbo.handle, size, gl.Ptr(nil), gl.MAP_PERSISTENT_BIT|gl.MAP_READ_BIT)
 
-	pbo.data = (*[1 << 30]byte)(pbo.memPointer)[: w*h*3 : w*h*3]
+	pbo.memPointer = gl.MapNamedBufferRange(pbo.handle, 0, size, gl.MAP_PERSISTENT_BIT|gl.MAP_READ_BIT)
+
+	pbo.data = (*[1 << 30]byte)(pbo.memPointer)[:size:size]
 
 	return pbo
 }
 
+// releasePBOs deletes buffers left from the previous recording, their size may not match the new one
+func releasePBOs() {
+	for _, pbo := range pboPool {
+		gl.UnmapNamedBuffer(pbo.handle)
+		gl.DeleteBuffers(1, &pbo.handle)
+	}
+
+	pboPool = pboPool[:0]
+	syncPool = syncPool[:0]
+}
+
 var pboSync *sync.RWMutex
 var pboPool = make([]*PBO, 0)
 
@@ -74,7 +91,7 @@ func precheck() {
 		}
 	}
 
-	vcodec := settings.Recording.Encoder
+	vcodec := getVideoEncoder()
 	acodec := settings.Recording.AudioCodec
 	vfound := false
 	afound := false
@@ -98,18 +115,40 @@ func precheck() {
 		panic(fmt.Sprintf("Video codec %q does not exist", vcodec))
 	}
 
-	if !afound {
+	// Audio is saved as WAV when the encoder is bypassed
+	if !afound && isVideoOutput() {
 		panic(fmt.Sprintf("Audio codec %q does not exist", acodec))
 	}
 }
 
-func StartFFmpeg(fps, _w, _h int) {
+func StartFFmpeg(fps, _w, _h int, output string) {
 	precheck()
 
 	log.Println("Starting encoding!")
 
 	w, h = _w, _h
 
+	bytesPerPixel = 3
+	if settings.Recording.Alpha {
+		bytesPerPixel = 4
+	}
+
+	outputName = output
+	if segment != nil {
+		outputName = segment.getOutputName()
+	} else if strings.TrimSpace(outputName) == "" {
+		outputName = "danser_" + time.Now().Format("2006-01-02_15-04-05")
+	}
+
+	// Directory can't be replaced by the finished sequence, it's better to fail before rendering than after
+	if !isVideoOutput() && GetContainer() == "" {
+		if files, err := ioutil.ReadDir(GetOutputPath(outputName)); err == nil && len(files) > 0 {
+			panic(fmt.Sprintf("Output directory %q already exists and is not empty, choose another name with -out", GetOutputPath(outputName)))
+		}
+	}
+
+	resetStatus(GetOutputPath(outputName))
+
 	err := os.MkdirAll(settings.Recording.OutputDir, 0755)
 	if err != nil && !os.IsExist(err) {
 		panic(err)
@@ -120,8 +159,6 @@ func StartFFmpeg(fps, _w, _h int) {
 
 	filename = hex.EncodeToString(b)
 
-	split := strings.Split(settings.Recording.EncoderOptions, " ")
-
 	filters := strings.TrimSpace(settings.Recording.Filters)
 	if len(filters) > 0 {
 		filters = "," + filters
@@ -131,29 +168,25 @@ func StartFFmpeg(fps, _w, _h int) {
 		fps /= settings.Recording.MotionBlur.OversampleMultiplier
 	}
 
+	outputFPS = fps
+
+	singlePass = checkSinglePass()
+
 	options := []string{
 		"-y", //(optional) overwrite output file if it exists
 		"-f", "rawvideo",
 		"-vcodec", "rawvideo",
 		"-s", fmt.Sprintf("%dx%d", w, h), //size of one frame
-		"-pix_fmt", "rgb24",
+		"-pix_fmt", inputPixelFormat(),
 		"-r", strconv.Itoa(fps), //frames per second
 		"-i", "-", //The input comes from a pipe
-		"-vf", "vflip" + filters,
-		"-profile:v", settings.Recording.Profile,
-		"-preset", settings.Recording.Preset,
-		"-an", //Tells FFMPEG not to expect any audio
-		"-vcodec", settings.Recording.Encoder,
-		"-color_range", "1",
-		"-colorspace", "1",
-		"-color_trc", "1",
-		"-color_primaries", "1",
-		"-movflags", "+write_colr",
-		"-pix_fmt", settings.Recording.PixelFormat,
 	}
 
-	options = append(options, split...)
-	options = append(options, filepath.Join(settings.Recording.OutputDir, filename+"."+settings.Recording.Container))
+	if isVideoOutput() {
+		options = append(options, videoOutput(filters)...)
+	} else {
+		options = append(options, sequenceOutput(filters)...)
+	}
 
 	log.Println("Running ffmpeg with options:", options)
 
@@ -167,12 +200,26 @@ func StartFFmpeg(fps, _w, _h int) {
 		panic(err)
 	}
 
+	if singlePass {
+		startAudio()
+	}
+
 	err = cmd.Start()
 	if err != nil {
 		panic(err)
 	}
 
+	if singlePass {
+		// ffmpeg has its own copy of reading end
+		cmd.ExtraFiles[0].Close()
+	}
+
+	frameNumber = -1
+	videoFrame = -1
+
 	mainthread.Call(func() {
+		releasePBOs()
+
 		for i := 0; i < MaxBuffers; i++ {
 			pboPool = append(pboPool, createPBO())
 		}
@@ -207,9 +254,60 @@ func StartFFmpeg(fps, _w, _h int) {
 	}()
 }
 
-func StopFFmpeg() {
+// videoOutput returns options encoding video with Recording.Encoder, audio is muxed in single pass mode
+func videoOutput(filters string) (options []string) {
+	movFlags := "+write_colr"
+
+	if singlePass {
+		options = append(options, audioInput()...)
+		options = append(options, metadataInput(2)...)
+		options = append(options, audioOutput()...)
+
+		if settings.Recording.Container == "mp4" {
+			movFlags += "+faststart"
+		}
+
+		movFlags += metadataMovFlags()
+	} else {
+		options = append(options, "-an") //Tells FFMPEG not to expect any audio
+	}
+
+	if settings.Recording.Alpha && !hasAlpha(settings.Recording.PixelFormat) {
+		log.Println(fmt.Sprintf("Pixel format %q doesn't have alpha channel, video won't be transparent", settings.Recording.PixelFormat))
+	}
+
+	options = append(options, "-vf", videoFilters(filters))
+
+	// Not every encoder has profiles and presets (e.g. prores_ks and libvpx-vp9 used for transparent videos)
+	if settings.Recording.Profile != "" {
+		options = append(options, "-profile:v", settings.Recording.Profile)
+	}
+
+	if settings.Recording.Preset != "" {
+		options = append(options, "-preset", settings.Recording.Preset)
+	}
+
+	options = append(options,
+		"-vcodec", settings.Recording.Encoder,
+		"-color_range", "1",
+		"-colorspace", "1",
+		"-color_trc", "1",
+		"-color_primaries", "1",
+		"-movflags", movFlags,
+		"-pix_fmt", settings.Recording.PixelFormat,
+	)
+
+	options = append(options, strings.Split(settings.Recording.EncoderOptions, " ")...)
+
+	return append(options, getPath(filename, GetContainer()))
+}
+
+// StopFFmpeg waits for remaining frames and closes ffmpeg. In single pass, image and intermediate modes the output is ready afterwards.
+func StopFFmpeg() error {
 	log.Println("Finishing rendering...")
 
+	setStage(StageFinishing)
+
 	for len(syncPool) > 0 {
 		CheckData()
 	}
@@ -223,9 +321,66 @@ func StopFFmpeg() {
 
 	log.Println("Pipe closed.")
 
-	cmd.Wait()
+	if singlePass {
+		stopAudio()
+	}
+
+	err := cmd.Wait()
 
 	log.Println("Ffmpeg finished.")
+
+	if singlePass || !isVideoOutput() {
+		removeMetadata()
+	}
+
+	if !singlePass && isVideoOutput() {
+		return err
+	}
+
+	if err != nil {
+		log.Println("ffmpeg finished abruptly! Please check if you have enough storage or audio bitrate is entered correctly.")
+	}
+
+	return finishOutput(getPath(filename, GetContainer()), err)
+}
+
+// hasAlpha checks if ffmpeg pixel format has alpha channel, like yuva420p, rgba or gbrap
+func hasAlpha(pixFmt string) bool {
+	for _, prefix := range []string{"yuva", "rgba", "bgra", "argb", "abgr", "gbrap", "ya"} {
+		if strings.HasPrefix(pixFmt, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inputPixelFormat() string {
+	if settings.Recording.Alpha {
+		return "rgba"
+	}
+
+	return "rgb24"
+}
+
+// videoFilters returns -vf chain applied to read frames. Frames are drawn premultiplied so with alpha
+// they have to be converted to straight alpha, EXR is the only format storing them premultiplied.
+func videoFilters(filters string) string {
+	chain := "vflip"
+
+	if settings.Recording.Alpha && (getOutputType() != OutputImages || getImageFormat() != "exr") {
+		chain += ",unpremultiply=inplace=1"
+	}
+
+	return chain + filters
+}
+
+func readFormat() uint32 {
+	if settings.Recording.Alpha {
+		return gl.RGBA
+	}
+
+	return gl.RGB
 }
 
 func PreFrame() {
@@ -237,6 +392,10 @@ func PreFrame() {
 var frameNumber = int64(-1)
 
 func MakeFrame() {
+	if singlePass {
+		pushAudio()
+	}
+
 	frameNumber++
 
 	if settings.Recording.MotionBlur.Enabled {
@@ -245,7 +404,15 @@ func MakeFrame() {
 		if frameNumber%int64(settings.Recording.MotionBlur.OversampleMultiplier) != 0 {
 			return
 		}
+	}
+
+	videoFrame++
 
+	if !isFrameRecorded() {
+		return
+	}
+
+	if settings.Recording.MotionBlur.Enabled {
 		blend.Blend()
 	}
 
@@ -266,7 +433,7 @@ func MakeFrame() {
 	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, pbo.handle)
 
 	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
-	gl.ReadPixels(0, 0, int32(w), int32(h), gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(nil))
+	gl.ReadPixels(0, 0, int32(w), int32(h), readFormat(), gl.UNSIGNED_BYTE, gl.Ptr(nil))
 
 	pbo.sync = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
 
@@ -297,6 +464,8 @@ func CheckData() {
 					panic(err)
 				}
 
+				atomic.AddInt64(&framesWritten, 1)
+
 				pboSync.Lock()
 				pboPool = append(pboPool, pbo)
 				pboSync.Unlock()
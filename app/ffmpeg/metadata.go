@@ -0,0 +1,151 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/settings"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Chapter times are in milliseconds since the start of the video
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type Metadata struct {
+	Artist     string
+	Title      string
+	Difficulty string
+	Mapper     string
+	Player     string
+	Mods       string
+
+	Chapters []Chapter
+}
+
+var metadata *Metadata
+
+// SetMetadata sets tags and chapters embedded in the next recorded video, nil disables them
+func SetMetadata(meta *Metadata) {
+	metadata = meta
+}
+
+func getMetadataPath() string {
+	return filepath.Join(settings.Recording.OutputDir, filename+".ffmeta")
+}
+
+// supportsChapters returns true if container can store chapters
+func supportsChapters(container string) bool {
+	switch container {
+	case "mp4", "mov", "mkv", "webm":
+		return true
+	}
+
+	return false
+}
+
+func writeMetadata(path string) error {
+	return ioutil.WriteFile(path, []byte(formatMetadata(metadata)), 0644)
+}
+
+// metadataInput writes ffmetadata file and returns options mapping it as input with given index.
+// If metadata is not set, the file saved by the last segment is used if it exists.
+func metadataInput(index int) []string {
+	if metadata != nil {
+		if err := writeMetadata(getMetadataPath()); err != nil {
+			log.Println("Failed to write metadata, video will be saved without it:", err)
+			return nil
+		}
+	} else if _, err := os.Stat(getMetadataPath()); err != nil {
+		return nil
+	}
+
+	options := []string{
+		"-f", "ffmetadata",
+		"-i", getMetadataPath(),
+	}
+
+	options = append(options, "-map_metadata", fmt.Sprint(index))
+
+	if supportsChapters(GetContainer()) {
+		options = append(options, "-map_chapters", fmt.Sprint(index))
+	} else {
+		options = append(options, "-map_chapters", "-1")
+	}
+
+	return options
+}
+
+// metadataMovFlags returns movflags needed to store non-standard tags in mp4/mov
+func metadataMovFlags() string {
+	switch GetContainer() {
+	case "mp4", "mov":
+		return "+use_metadata_tags"
+	}
+
+	return ""
+}
+
+func removeMetadata() {
+	_ = os.Remove(getMetadataPath())
+}
+
+func formatMetadata(meta *Metadata) string {
+	var builder strings.Builder
+
+	builder.WriteString(";FFMETADATA1\n")
+
+	tags := [][2]string{
+		{"artist", meta.Artist},
+		{"title", meta.Title},
+		{"difficulty", meta.Difficulty},
+		{"mapper", meta.Mapper},
+		{"player", meta.Player},
+		{"mods", meta.Mods},
+	}
+
+	for _, tag := range tags {
+		if tag[1] == "" {
+			continue
+		}
+
+		builder.WriteString(tag[0] + "=" + escapeMetadata(tag[1]) + "\n")
+	}
+
+	for _, chapter := range meta.Chapters {
+		start := int64(math.Max(0, math.Round(chapter.Start)))
+		end := int64(math.Round(chapter.End))
+
+		if end <= start {
+			continue
+		}
+
+		builder.WriteString("\n[CHAPTER]\nTIMEBASE=1/1000\n")
+		builder.WriteString(fmt.Sprintf("START=%d\nEND=%d\n", start, end))
+		builder.WriteString("title=" + escapeMetadata(chapter.Title) + "\n")
+	}
+
+	return builder.String()
+}
+
+// escapeMetadata escapes characters special to ffmetadata format
+func escapeMetadata(value string) string {
+	var builder strings.Builder
+
+	for _, r := range value {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			builder.WriteRune('\\')
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,246 @@
+package ffmpeg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/settings"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	OutputVideo        = "video"
+	OutputImages       = "images"
+	OutputIntermediate = "intermediate"
+)
+
+var outputFPS int
+
+func getOutputType() string {
+	switch outputType := strings.ToLower(settings.Recording.OutputType); outputType {
+	case "", OutputVideo:
+		return OutputVideo
+	case OutputImages, OutputIntermediate:
+		return outputType
+	}
+
+	panic(fmt.Sprintf("Unknown output type %q, it has to be one of: %s, %s, %s", settings.Recording.OutputType, OutputVideo, OutputImages, OutputIntermediate))
+}
+
+func isVideoOutput() bool {
+	return getOutputType() == OutputVideo
+}
+
+func getImageFormat() string {
+	switch format := strings.ToLower(settings.Recording.ImageFormat); format {
+	case "png", "tiff", "exr":
+		return format
+	}
+
+	panic(fmt.Sprintf("Unsupported image format %q, it has to be one of: png, tiff, exr", settings.Recording.ImageFormat))
+}
+
+func getIntermediateCodec() string {
+	switch codec := strings.ToLower(settings.Recording.IntermediateCodec); codec {
+	case "ffv1", "prores":
+		return codec
+	}
+
+	panic(fmt.Sprintf("Unsupported intermediate codec %q, it has to be one of: ffv1, prores", settings.Recording.IntermediateCodec))
+}
+
+// GetContainer returns extension of the recorded file, image sequences are saved to a directory so it's empty for them
+func GetContainer() string {
+	switch getOutputType() {
+	case OutputImages:
+		return ""
+	case OutputIntermediate:
+		if getIntermediateCodec() == "prores" {
+			return "mov"
+		}
+
+		return "mkv"
+	}
+
+	return settings.Recording.Container
+}
+
+// GetOutputPath returns path of the recording with given name
+func GetOutputPath(name string) string {
+	return getPath(name, GetContainer())
+}
+
+func getPath(name, container string) string {
+	path := filepath.Join(settings.Recording.OutputDir, name)
+	if container != "" {
+		path += "." + container
+	}
+
+	return path
+}
+
+// getVideoEncoder returns ffmpeg encoder used for current output type
+func getVideoEncoder() string {
+	switch getOutputType() {
+	case OutputImages:
+		return getImageFormat()
+	case OutputIntermediate:
+		if getIntermediateCodec() == "prores" {
+			return "prores_ks"
+		}
+
+		return "ffv1"
+	}
+
+	return settings.Recording.Encoder
+}
+
+// encoderOptions returns options passed to the encoder in image and intermediate modes, pixel format depends on alpha being read
+func encoderOptions() []string {
+	alpha := settings.Recording.Alpha
+
+	pixFmt := func(opaque, transparent string) string {
+		if alpha {
+			return transparent
+		}
+
+		return opaque
+	}
+
+	if getOutputType() == OutputIntermediate {
+		if getIntermediateCodec() == "prores" {
+			profile := "3" // HQ
+			if alpha {
+				profile = "4" // 4444
+			}
+
+			return []string{"-profile:v", profile, "-pix_fmt", pixFmt("yuv422p10le", "yuva444p10le")}
+		}
+
+		// FFV1 stores RGB losslessly, every frame is a keyframe so it can be cut anywhere
+		return []string{"-level", "3", "-g", "1", "-slices", "16", "-slicecrc", "1", "-pix_fmt", pixFmt("bgr0", "bgra")}
+	}
+
+	if getImageFormat() == "exr" {
+		return []string{"-pix_fmt", pixFmt("gbrpf32le", "gbrapf32le")}
+	}
+
+	return []string{"-pix_fmt", pixFmt("rgb24", "rgba")}
+}
+
+// sequenceOutput returns options for image and intermediate modes, they don't have audio
+func sequenceOutput(filters string) (options []string) {
+	if getOutputType() == OutputIntermediate {
+		options = append(options, metadataInput(1)...)
+
+		if flags := metadataMovFlags(); flags != "" {
+			options = append(options, "-movflags", flags)
+		}
+	}
+
+	options = append(options,
+		"-an",
+		"-vf", videoFilters(filters),
+		"-vcodec", getVideoEncoder(),
+	)
+
+	options = append(options, encoderOptions()...)
+
+	if getOutputType() != OutputImages {
+		return append(options, getPath(filename, GetContainer()))
+	}
+
+	dir := filepath.Join(settings.Recording.OutputDir, filename)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		panic(err)
+	}
+
+	return append(options,
+		"-f", "image2",
+		"-start_number", "0",
+		filepath.Join(dir, "%08d."+getImageFormat()),
+	)
+}
+
+// Timing describes how audio saved next to images or intermediate file lines up with frames
+type Timing struct {
+	FPS    int   `json:"fps"`
+	Frames int64 `json:"frames"`
+	Width  int   `json:"width"`
+	Height int   `json:"height"`
+
+	// Duration of the frames in seconds
+	Duration float64 `json:"duration"`
+
+	// Audio starts AudioOffset seconds after the first frame
+	Audio       string  `json:"audio"`
+	AudioOffset float64 `json:"audio_offset"`
+
+	Chapters []Chapter `json:"chapters,omitempty"`
+}
+
+// finishSequence moves audio next to the output and saves the timing file, frames were saved by StopFFmpeg already
+func finishSequence() error {
+	if GetStatus().Stage == StageFailed {
+		// Frames left under temporary name by failed rename still need their audio
+		if _, err := os.Stat(getPath(filename, GetContainer())); err == nil {
+			log.Println("Audio was left at:", getPath(filename, "wav"))
+
+			return errors.New("failed to move frames to the output")
+		}
+
+		_ = os.Remove(getPath(filename, "wav"))
+
+		return errors.New("ffmpeg failed to save frames")
+	}
+
+	setStage(StageMuxing)
+
+	audioName := getOutputName() + ".wav"
+
+	err := os.Rename(getPath(filename, "wav"), filepath.Join(settings.Recording.OutputDir, audioName))
+	if err != nil {
+		log.Println("Failed to save audio:", err)
+		setStage(StageFailed)
+
+		return err
+	}
+
+	frames := GetStatus().Frames
+
+	timing := Timing{
+		FPS:      outputFPS,
+		Frames:   frames,
+		Width:    w,
+		Height:   h,
+		Duration: float64(frames) / float64(outputFPS),
+		Audio:    audioName,
+	}
+
+	if metadata != nil {
+		timing.Chapters = metadata.Chapters
+	}
+
+	data, err := json.MarshalIndent(timing, "", "\t")
+	if err == nil {
+		err = ioutil.WriteFile(getPath(getOutputName(), "timing.json"), data, 0644)
+	}
+
+	if err != nil {
+		log.Println("Failed to save timing file:", err)
+		setStage(StageFailed)
+
+		return err
+	}
+
+	log.Println("Audio saved to:", filepath.Join(settings.Recording.OutputDir, audioName))
+
+	setStage(StageFinished)
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package ffmpeg
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/bass"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// segmentWarmUp is the time in milliseconds drawn before the first frame of a segment,
+// effects depending on previous frames (cursor trails, motion blur) have to settle before it's recorded.
+const segmentWarmUp = 2000.0
+
+// Segment is a part of the video recorded by a child process of -segments.
+// Like with -start, the beatmap is simulated without drawing up to a short warm-up before segment's range,
+// frames within the range are identical to the ones recorded in one go. The last segment records until the end and saves audio and metadata.
+type Segment struct {
+	Index int
+	Count int
+
+	// Name of the recording, segments are saved as <Name>_<Index>
+	Name string
+
+	from, to int64
+}
+
+func ParseSegment(value string) (*Segment, error) {
+	split := strings.SplitN(value, "/", 3)
+	if len(split) != 3 || split[2] == "" {
+		return nil, fmt.Errorf("invalid segment %q, it has to be index/count/name", value)
+	}
+
+	index, err1 := strconv.Atoi(split[0])
+	count, err2 := strconv.Atoi(split[1])
+
+	if err1 != nil || err2 != nil || count < 1 || index < 0 || index >= count {
+		return nil, fmt.Errorf("invalid segment %q, index has to be between 0 and count-1", value)
+	}
+
+	return &Segment{
+		Index: index,
+		Count: count,
+		Name:  split[2],
+	}, nil
+}
+
+func (segment *Segment) String() string {
+	return fmt.Sprintf("%d/%d/%s", segment.Index, segment.Count, segment.Name)
+}
+
+func (segment *Segment) IsLast() bool {
+	return segment.Index == segment.Count-1
+}
+
+func (segment *Segment) getOutputName() string {
+	return fmt.Sprintf("%s_%d", segment.Name, segment.Index)
+}
+
+var segment *Segment
+
+var videoFrame int64
+
+// SetSegment makes StartFFmpeg record only given part of the video
+func SetSegment(s *Segment) {
+	segment = s
+}
+
+func IsSegment() bool {
+	return segment != nil
+}
+
+// SetVideoLength sets expected length of the video in milliseconds, segments split it into equal parts.
+// It has to be deterministic as every segment calculates all ranges on its own.
+func SetVideoLength(length float64) {
+	if segment == nil {
+		return
+	}
+
+	total := int64(math.Ceil(length / 1000 * float64(outputFPS)))
+
+	segment.from = total * int64(segment.Index) / int64(segment.Count)
+	segment.to = total * int64(segment.Index+1) / int64(segment.Count)
+
+	if segment.IsLast() {
+		segment.to = math.MaxInt64
+	}
+
+	log.Println(fmt.Sprintf("Recording segment %d of %d, frames from %d", segment.Index+1, segment.Count, segment.from))
+}
+
+// IsFrameSkipped returns true if the next frame is before segment's warm-up, it doesn't have to be drawn
+func IsFrameSkipped() bool {
+	if segment == nil {
+		return false
+	}
+
+	multiplier := int64(1)
+	if settings.Recording.MotionBlur.Enabled {
+		multiplier = int64(settings.Recording.MotionBlur.OversampleMultiplier)
+	}
+
+	// Video frame the next (oversampled) frame contributes to
+	next := (frameNumber + multiplier) / multiplier
+
+	return next < segment.from-int64(math.Ceil(segmentWarmUp/1000*float64(outputFPS)))
+}
+
+// SkipFrame counts a frame that wasn't drawn so following frames keep the same numbers as in a full recording
+func SkipFrame() {
+	frameNumber++
+
+	if !settings.Recording.MotionBlur.Enabled || frameNumber%int64(settings.Recording.MotionBlur.OversampleMultiplier) == 0 {
+		videoFrame++
+	}
+}
+
+// isFrameRecorded returns false for frames that are drawn only to bring the segment to the same state as a full recording
+func isFrameRecorded() bool {
+	return segment == nil || videoFrame >= segment.from
+}
+
+// IsSegmentFinished returns true if all frames of the segment were recorded, the last segment is finished by the end of the beatmap
+func IsSegmentFinished() bool {
+	return segment != nil && videoFrame >= segment.to-1
+}
+
+// FinishSegment saves recorded segment, the last one also saves audio and metadata used when segments are joined
+func FinishSegment(err error) error {
+	if err != nil {
+		log.Println("ffmpeg finished abruptly! Please check if you have enough storage.")
+	}
+
+	if err = finishOutput(getPath(filename, GetContainer()), err); err != nil || !segment.IsLast() {
+		return err
+	}
+
+	bass.SaveToFile(getPath(segment.Name, "wav"))
+
+	if metadata != nil {
+		if err = writeMetadata(getPath(segment.Name, "ffmeta")); err != nil {
+			log.Println("Failed to write metadata, video will be saved without it:", err)
+		}
+	}
+
+	return nil
+}
+
+// RecordSegments records the video in count parts by running danser with given arguments in parallel and joins them without re-encoding
+func RecordSegments(count int, executable string, args []string, output string) error {
+	if !isVideoOutput() {
+		return errors.New("segmented recording supports only video output type")
+	}
+
+	precheck()
+
+	// Every segment has its own progress and can't be cancelled on its own
+	if settings.Recording.StatusAddress != "" {
+		log.Println("Status server is not available in segmented recording, Recording.StatusAddress is ignored")
+	}
+
+	outputName = output
+	if strings.TrimSpace(outputName) == "" {
+		outputName = "danser_" + time.Now().Format("2006-01-02_15-04-05")
+	}
+
+	resetStatus(GetOutputPath(outputName))
+
+	if err := os.MkdirAll(settings.Recording.OutputDir, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	filename = hex.EncodeToString(b)
+
+	// Segments share the name with this process so Combine finds audio and metadata saved by the last one
+	segments := make([]*Segment, count)
+
+	errs := make([]error, count)
+
+	wg := &sync.WaitGroup{}
+
+	for i := range segments {
+		segments[i] = &Segment{Index: i, Count: count, Name: filename}
+
+		cmd := exec.Command(executable, append(args, "-segment="+segments[i].String())...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		log.Println(fmt.Sprintf("Starting segment %d of %d...", i+1, count))
+
+		if err := cmd.Start(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			errs[i] = cmd.Wait()
+			wg.Done()
+		}(i)
+	}
+
+	wg.Wait()
+
+	setStage(StageFinishing)
+
+	var err error
+
+	for i, e := range errs {
+		if e != nil {
+			err = fmt.Errorf("segment %d failed: %s", i+1, e)
+			break
+		}
+	}
+
+	listPath := getPath(filename, "txt")
+
+	if err == nil {
+		var list strings.Builder
+
+		for _, s := range segments {
+			// Paths in concat list are relative to the list itself
+			list.WriteString("file '" + strings.ReplaceAll(filepath.Base(getPath(s.getOutputName(), GetContainer())), "'", `'\''`) + "'\n")
+		}
+
+		err = ioutil.WriteFile(listPath, []byte(list.String()), 0644)
+	}
+
+	if err == nil {
+		err = combine("-f", "concat", "-safe", "0", "-i", listPath)
+	} else {
+		setStage(StageFailed)
+
+		removeMetadata()
+		_ = os.Remove(getPath(filename, "wav"))
+	}
+
+	for _, s := range segments {
+		_ = os.Remove(getPath(s.getOutputName(), GetContainer()))
+	}
+
+	_ = os.Remove(listPath)
+
+	return err
+}
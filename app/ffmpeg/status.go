@@ -0,0 +1,192 @@
+package ffmpeg
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StageRecording = "recording"
+	StageFinishing = "finishing"
+	StageMuxing    = "muxing"
+	StageFinished  = "finished"
+	StageFailed    = "failed"
+)
+
+// Status describes the state of current recording, times are in milliseconds unless noted otherwise
+type Status struct {
+	Stage string `json:"stage"`
+
+	Time     float64 `json:"time"`
+	End      float64 `json:"end"`
+	Progress float64 `json:"progress"`
+
+	Frames int64   `json:"frames"`
+	FPS    float64 `json:"fps"`
+
+	// Estimated time left in seconds, -1 if it's not known yet
+	ETA float64 `json:"eta"`
+
+	// Frames waiting to be written to ffmpeg and frames still being read from GPU
+	Queue   int `json:"queue"`
+	Pending int `json:"pending"`
+
+	Output    string `json:"output"`
+	Cancelled bool   `json:"cancelled"`
+}
+
+var ErrCancelled = errors.New("recording cancelled")
+
+var statusMutex = &sync.Mutex{}
+var status = Status{Stage: StageRecording, ETA: -1}
+
+var statusServer *http.Server
+
+var framesWritten int64
+var cancelled int32
+var recordStart time.Time
+
+// StartStatusServer serves recording status on Recording.StatusAddress if it's set.
+// GET /status returns Status as JSON, POST /cancel stops recording and keeps what was recorded so far.
+func StartStatusServer() {
+	if statusServer != nil || settings.Recording.StatusAddress == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeStatus(w)
+	})
+
+	mux.HandleFunc("/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		statusMutex.Lock()
+		recording := status.Stage == StageRecording
+		statusMutex.Unlock()
+
+		// Video is already complete after recording stage, there's nothing to cut off
+		if !recording {
+			http.Error(w, "recording already finished", http.StatusConflict)
+			return
+		}
+
+		if atomic.CompareAndSwapInt32(&cancelled, 0, 1) {
+			log.Println("Recording cancel requested")
+		}
+
+		writeStatus(w)
+	})
+
+	statusServer = &http.Server{
+		Addr:    settings.Recording.StatusAddress,
+		Handler: mux,
+	}
+
+	go func() {
+		log.Println("Status server listening on:", settings.Recording.StatusAddress)
+
+		if err := statusServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Println("Status server failed:", err)
+		}
+	}()
+}
+
+func writeStatus(w http.ResponseWriter) {
+	data, err := json.Marshal(GetStatus())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func GetStatus() Status {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	current := status
+	current.Frames = atomic.LoadInt64(&framesWritten)
+	current.Cancelled = IsCancelled()
+
+	if elapsed := time.Since(recordStart).Seconds(); !recordStart.IsZero() && elapsed > 0 && current.Stage == StageRecording {
+		current.FPS = float64(current.Frames) / elapsed
+
+		if current.Progress > 0 {
+			current.ETA = math.Max(0, elapsed/current.Progress-elapsed)
+		}
+	}
+
+	return current
+}
+
+// UpdateProgress has to be called from the main thread after each recorded frame
+func UpdateProgress(time, end, progress float64) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	status.Time = time
+	status.End = end
+	status.Progress = progress
+	status.Queue = len(queue)
+	status.Pending = len(syncPool)
+}
+
+// IsCancelled returns true if recording was cancelled through the status server
+func IsCancelled() bool {
+	return atomic.LoadInt32(&cancelled) == 1
+}
+
+func resetStatus(output string) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	status = Status{
+		Stage:  StageRecording,
+		ETA:    -1,
+		Output: output,
+	}
+
+	atomic.StoreInt64(&framesWritten, 0)
+	atomic.StoreInt32(&cancelled, 0)
+
+	recordStart = time.Now()
+}
+
+func setStage(stage string) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	status.Stage = stage
+
+	if stage != StageRecording {
+		status.ETA = -1
+		status.Queue = 0
+		status.Pending = 0
+	}
+}
+
+func setOutput(output string) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	status.Output = output
+}
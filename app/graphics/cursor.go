@@ -72,7 +72,7 @@ type Cursor struct {
 	IsPlayer      bool
 	IsAutoplay    bool
 
-	OldSpinnerScoring bool
+	ReplayVersion int32 // osu! version the replay was made with, 0 if cursor doesn't play a replay
 
 	LastFrameTime    int64 //
 	CurrentFrameTime int64 //
@@ -102,10 +102,25 @@ type Cursor struct {
 }
 
 func NewCursor() *Cursor {
-	if cursorFbo == nil {
+	if settings.HEADLESS {
+		cursor := &Cursor{Position: vector.NewVec2f(100, 100)}
+		cursor.scale = animation.NewGlider(1.0)
+
+		return cursor
+	}
+
+	// Resolution may change between recordings in -queue
+	if cursorFbo == nil || cursorFbo.GetWidth() != int(settings.Graphics.GetWidth()) || cursorFbo.GetHeight() != int(settings.Graphics.GetHeight()) {
+		if cursorFbo != nil {
+			cursorFbo.Dispose()
+			cursorSpaceFbo.Dispose()
+		}
+
 		initCursor()
 	}
 
+	osuRect = Camera.GetWorldRect()
+
 	cursor := &Cursor{Position: vector.NewVec2f(100, 100)}
 	cursor.scale = animation.NewGlider(1.0)
 
@@ -127,13 +142,18 @@ func NewCursor() *Cursor {
 
 func (cursor *Cursor) SetPos(pt vector.Vector2f) {
 	cursor.RawPosition = pt
+	cursor.SetDisplayPos(pt)
+}
+
+// SetDisplayPos changes only the drawn position, RawPosition used by rulesets stays untouched
+func (cursor *Cursor) SetDisplayPos(pt vector.Vector2f) {
 	tmp := pt
 
 	if cursor.InvertDisplay {
 		tmp.Y = 384 - tmp.Y
 	}
 
-	if settings.Cursor.BounceOnEdges && settings.DIVIDES <= 2 {
+	if settings.Cursor.BounceOnEdges && settings.DIVIDES <= 2 && !settings.HEADLESS {
 		tmp.X -= osuRect.MinX
 		tmp.Y -= osuRect.MinY
 		tmp.X = math32.Mod(tmp.X, 2*(osuRect.MaxX-osuRect.MinX))
@@ -174,6 +194,10 @@ func (cursor *Cursor) SetScreenPos(pt vector.Vector2f) {
 }
 
 func (cursor *Cursor) Update(delta float64) {
+	if settings.HEADLESS {
+		return
+	}
+
 	delta = math.Abs(delta)
 	cursor.time += delta
 
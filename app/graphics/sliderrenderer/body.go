@@ -39,7 +39,8 @@ type Body struct {
 	distortionMatrix mgl32.Mat4
 }
 
-func NewBody(curve *curves.MultiCurve, hardRock bool, hitCircleRadius float32) *Body {
+// NewBody creates slider's body, modifyPoint is used to apply position mods to points of the curve
+func NewBody(curve *curves.MultiCurve, modifyPoint func(point vector.Vector2f) vector.Vector2f, hitCircleRadius float32) *Body {
 	if sliderShader == nil {
 		InitRenderer()
 	}
@@ -50,7 +51,7 @@ func NewBody(curve *curves.MultiCurve, hardRock bool, hitCircleRadius float32) *
 	body.radius = hitCircleRadius
 	body.previousStart = -1
 
-	body.setupPoints(curve, hardRock)
+	body.setupPoints(curve, modifyPoint)
 
 	if len(body.points) > 0 {
 		body.setupVAO()
@@ -59,25 +60,16 @@ func NewBody(curve *curves.MultiCurve, hardRock bool, hitCircleRadius float32) *
 	return body
 }
 
-func pointAt(curve *curves.MultiCurve, t float32, hardRock bool) vector.Vector2f {
-	point := curve.PointAt(t)
-	if hardRock {
-		point.Y = 384 - point.Y
-	}
-
-	return point
-}
-
-func (body *Body) setupPoints(curve *curves.MultiCurve, hardRock bool) {
+func (body *Body) setupPoints(curve *curves.MultiCurve, modifyPoint func(point vector.Vector2f) vector.Vector2f) {
 	length := curve.GetLength()
 	numPoints := math32.Min(math32.Ceil(length*float32(settings.Objects.Sliders.Quality.PathLevelOfDetail)/100.0), maxSliderPoints)
 
 	if numPoints > 0 {
-		body.topLeft = pointAt(curve, 0, hardRock)
-		body.bottomRight = pointAt(curve, 0, hardRock)
+		body.topLeft = modifyPoint(curve.PointAt(0))
+		body.bottomRight = body.topLeft
 
 		for i := 0; i <= int(numPoints); i++ {
-			point := pointAt(curve, float32(i) / numPoints, hardRock)
+			point := modifyPoint(curve.PointAt(float32(i) / numPoints))
 
 			body.topLeft.X = math32.Min(body.topLeft.X, point.X)
 			body.topLeft.Y = math32.Min(body.topLeft.Y, point.Y)
@@ -74,15 +74,24 @@ func InitRenderer() {
 
 	colorVAO.Attach(colorShader)
 
+	initFramebuffer()
+
+	batch = batch2.NewQuadBatchSize(1)
+}
+
+func initFramebuffer() {
 	framebuffer = buffer.NewFrame(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()), false, true)
 	region := framebuffer.Texture().GetRegion()
 	fboSprite = sprite.NewSpriteSingle(&region, 0, vector.NewVec2d(settings.Graphics.GetWidthF()/2, settings.Graphics.GetHeightF()/2), bmath.Origin.Centre)
-	batch = batch2.NewQuadBatchSize(1)
 }
 
 func BeginRenderer() {
 	if sliderShader == nil {
 		InitRenderer()
+	} else if framebuffer.GetWidth() != int(settings.Graphics.GetWidth()) || framebuffer.GetHeight() != int(settings.Graphics.GetHeight()) {
+		// Resolution may change between recordings in -queue
+		framebuffer.Dispose()
+		initFramebuffer()
 	}
 
 	colorShader.Bind()
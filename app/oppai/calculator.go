@@ -0,0 +1,85 @@
+package oppai
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"math"
+)
+
+type MapStats struct {
+	MaxCombo, NSliders, NCircles, NObjects int
+}
+
+func GetMapStats(hitObjects []objects.IHitObject) (stats MapStats) {
+	for _, o := range hitObjects {
+		if s, ok := o.(*objects.Slider); ok {
+			stats.NSliders++
+			stats.MaxCombo += len(s.ScorePoints)
+		} else if _, ok := o.(*objects.Circle); ok {
+			stats.NCircles++
+		}
+
+		stats.MaxCombo++
+		stats.NObjects++
+	}
+
+	return
+}
+
+type PPResult struct {
+	Stars Stars
+	PP    PPv2
+	Stats MapStats
+
+	Combo                  int
+	N300, N100, N50, NMiss int
+	Accuracy               float64
+}
+
+// CalculatePP calculates star rating and pp of a score. Negative combo means full combo.
+func CalculatePP(hitObjects []objects.IHitObject, diff *difficulty.Difficulty, combo, n100, n50, nMiss int) PPResult {
+	return CalculatePPWithStars(CalculateSingle(hitObjects, diff, false), GetMapStats(hitObjects), diff, combo, n100, n50, nMiss)
+}
+
+// CalculatePPWithStars is CalculatePP for already calculated star rating, useful when many scores are checked for the same mods
+func CalculatePPWithStars(stars Stars, stats MapStats, diff *difficulty.Difficulty, combo, n100, n50, nMiss int) PPResult {
+	if combo < 0 {
+		combo = stats.MaxCombo
+	}
+
+	result := PPResult{
+		Stars: stars,
+		Stats: stats,
+		Combo: bmath.MinI(combo, stats.MaxCombo),
+		N100:  n100,
+		N50:   n50,
+		NMiss: nMiss,
+		N300:  bmath.MaxI(0, stats.NObjects-n100-n50-nMiss),
+	}
+
+	pp := &PPv2{}
+	result.PP = pp.PPv2x(stars.Aim, stars.Speed, stats.MaxCombo, stats.NSliders, stats.NCircles, stats.NObjects, result.Combo, result.N300, result.N100, result.N50, result.NMiss, diff, 1)
+	result.Accuracy = pp.accuracy * 100
+
+	return result
+}
+
+// HitsFromAccuracy finds amount of 100s and 50s closest to given accuracy (in percent)
+func HitsFromAccuracy(nObjects, nMiss int, accuracy float64) (n100, n50 int) {
+	nMiss = bmath.MinI(nMiss, nObjects)
+	max300 := nObjects - nMiss
+
+	accuracy = bmath.ClampF64(accuracy, 0, 100) / 100
+
+	n100 = int(math.Round(-3 * ((accuracy-1)*float64(nObjects) + float64(nMiss)) * 0.5))
+
+	if n100 > max300 {
+		// accuracy is lower than all 100s, use 50s instead
+		n100 = 0
+		n50 = int(math.Round(-6 * ((accuracy-1)*float64(nObjects) + float64(nMiss)) * 0.2))
+		n50 = bmath.MinI(max300, n50)
+	}
+
+	return bmath.MaxI(0, n100), bmath.MaxI(0, n50)
+}
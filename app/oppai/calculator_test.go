@@ -0,0 +1,44 @@
+package oppai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHitsFromAccuracy(t *testing.T) {
+	tests := []struct {
+		name     string
+		nObjects int
+		nMiss    int
+		accuracy float64
+	}{
+		{"SS", 500, 0, 100},
+		{"high accuracy", 500, 0, 98.5},
+		{"high accuracy with misses", 1000, 12, 96.2},
+		{"all 100s", 300, 0, 100.0 / 3},
+		{"only 50s needed", 400, 0, 30},
+		{"low accuracy with misses", 800, 40, 25},
+		{"all 50s", 200, 0, 50.0 / 3},
+		{"short map", 10, 1, 70},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n100, n50 := HitsFromAccuracy(test.nObjects, test.nMiss, test.accuracy)
+
+			n300 := test.nObjects - test.nMiss - n100 - n50
+			if n300 < 0 {
+				t.Fatalf("too many hits: n100 = %d, n50 = %d, misses = %d, objects = %d", n100, n50, test.nMiss, test.nObjects)
+			}
+
+			accuracy := 100 * float64(300*n300+100*n100+50*n50) / float64(300*test.nObjects)
+
+			// Rounding to whole hits can miss by half of the largest step (300 -> 50)
+			tolerance := 100 * 125 / float64(300*test.nObjects)
+
+			if math.Abs(accuracy-test.accuracy) > tolerance {
+				t.Errorf("accuracy %.4f%% gives n300 = %d, n100 = %d, n50 = %d which is %.4f%%", test.accuracy, n300, n100, n50, accuracy)
+			}
+		})
+	}
+}
@@ -103,3 +103,47 @@ func CalculateStep(objects []objects.IHitObject, diff *difficulty.Difficulty, us
 
 	return stars
 }
+
+// Strain holds skill strains right after an object was processed and star rating of the map up to that object
+type Strain struct {
+	Time   float64
+	Object int
+	Aim    float64
+	Speed  float64
+	Total  float64
+	Stars  Stars
+}
+
+// Calculate strains of every object in a beatmap, first object has no strain
+func CalculateStrains(objects []objects.IHitObject, diff *difficulty.Difficulty, useFixedCalculations bool) []Strain {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(useFixedCalculations, diff)
+	speedSkill := skills.NewSpeedSkill(useFixedCalculations, diff)
+
+	strains := make([]Strain, 1, len(objects))
+	strains[0].Time = objects[0].GetStartTime()
+
+	for i, o := range diffObjects {
+		aimSkill.Process(o)
+		speedSkill.Process(o)
+
+		aim := aimSkill.CurrentStrain
+		speed := speedSkill.CurrentStrain
+
+		strains = append(strains, Strain{
+			Time:   objects[i+1].GetStartTime(),
+			Object: i + 1,
+			Aim:    aim,
+			Speed:  speed,
+			Total:  aim + speed + math.Abs(speed-aim)*ExtremeScalingFactor,
+			Stars:  getStars(aimSkill, speedSkill, diff),
+		})
+	}
+
+	return strains
+}
@@ -104,6 +104,8 @@ func (pp *PPv2) PPv2x(aimStars, speedStars float64,
 	speed := pp.computeSpeedValue()
 	accuracy := pp.computeAccuracyValue()
 
+	pp.Aim, pp.Speed, pp.Acc = aim, speed, accuracy
+
 	pp.Total = math.Pow(
 		math.Pow(aim, 1.1)+math.Pow(speed, 1.1)+
 			math.Pow(accuracy, 1.1),
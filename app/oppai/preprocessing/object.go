@@ -43,9 +43,9 @@ func NewDifficultyObject(hitObject, lastLastObject, lastObject objects.IHitObjec
 		BaseObject:     hitObject,
 		LastObject:     lastObject,
 		lastLastObject: lastLastObject,
-		DeltaTime:      (hitObject.GetStartTime() - lastObject.GetStartTime()) / d.Speed,
-		StartTime:      hitObject.GetStartTime() / d.Speed,
-		EndTime:        hitObject.GetEndTime() / d.Speed,
+		DeltaTime:      d.ToRealTime(hitObject.GetStartTime()) - d.ToRealTime(lastObject.GetStartTime()),
+		StartTime:      d.ToRealTime(hitObject.GetStartTime()),
+		EndTime:        d.ToRealTime(hitObject.GetEndTime()),
 	}
 
 	obj.setDistances()
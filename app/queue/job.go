@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Job describes a single video recorded by -queue.
+// Beatmap is selected the same way as with command line flags: by replay, id, md5 or artist/title/difficulty/creator.
+type Job struct {
+	Replay string `json:"replay,omitempty"`
+
+	ID         int64  `json:"id,omitempty"`
+	MD5        string `json:"md5,omitempty"`
+	Artist     string `json:"artist,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Creator    string `json:"creator,omitempty"`
+
+	Knockout bool   `json:"knockout,omitempty"`
+	Mods     string `json:"mods,omitempty"`
+
+	// Skin overrides Skin.CurrentSkin, Settings is the settings version loaded for this job (settings-<Settings>.json)
+	Skin     string `json:"skin,omitempty"`
+	Settings string `json:"settings,omitempty"`
+
+	// Start and End are given in seconds, End equal to 0 records until the end of the beatmap
+	Start float64 `json:"start,omitempty"`
+	End   float64 `json:"end,omitempty"`
+	Skip  bool    `json:"skip,omitempty"`
+
+	// Out is the name of recorded video, extension is managed by settings
+	Out string `json:"out,omitempty"`
+}
+
+func (job *Job) HasBeatmap() bool {
+	return job.Replay != "" || job.ID > 0 || (job.MD5+job.Artist+job.Title+job.Difficulty+job.Creator) != ""
+}
+
+func (job *Job) GetEnd() float64 {
+	if job.End <= 0 {
+		return math.Inf(1)
+	}
+
+	return job.End
+}
+
+// Load reads jobs from a JSON array. Jobs without output name are named after the queue file and their position in it.
+func Load(path string) ([]*Job, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+
+	if err = json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	outputs := make(map[string]int)
+
+	for i, job := range jobs {
+		if job == nil {
+			return nil, fmt.Errorf("job %d is empty", i+1)
+		}
+
+		if strings.TrimSpace(job.Out) == "" {
+			job.Out = baseName + "_" + strconv.Itoa(i+1)
+		}
+
+		// Finished jobs are recognized by their output, so names can't repeat
+		if j, exists := outputs[job.Out]; exists {
+			return nil, fmt.Errorf("jobs %d and %d have the same output name: %s", j+1, i+1, job.Out)
+		}
+
+		outputs[job.Out] = i
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+type Status string
+
+const (
+	Pending Status = "pending"
+	Running Status = "running"
+	Done    Status = "done"
+	Skipped Status = "skipped"
+	Failed  Status = "failed"
+)
+
+type Result struct {
+	Out    string `json:"out"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Duration of the job in seconds
+	Duration float64 `json:"duration"`
+}
+
+// Report keeps the outcome of every job. It's saved after each change, a job left as running means that danser crashed while recording it.
+type Report struct {
+	path string
+
+	Results []*Result `json:"results"`
+}
+
+// NewReport creates a report saved next to the queue file as <name>.report.json
+func NewReport(queuePath string, jobs []*Job) *Report {
+	report := &Report{
+		path: strings.TrimSuffix(queuePath, filepath.Ext(queuePath)) + ".report.json",
+	}
+
+	for _, job := range jobs {
+		report.Results = append(report.Results, &Result{
+			Out:    job.Out,
+			Status: Pending,
+		})
+	}
+
+	return report
+}
+
+func (report *Report) Set(index int, status Status, err error, duration float64) error {
+	result := report.Results[index]
+
+	result.Status = status
+	result.Duration = duration
+	result.Error = ""
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return report.Save()
+}
+
+func (report *Report) Count(status Status) (count int) {
+	for _, result := range report.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+
+	return
+}
+
+func (report *Report) GetPath() string {
+	return report.path
+}
+
+func (report *Report) Save() error {
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(report.path, data, 0644)
+}
@@ -0,0 +1,362 @@
+package catch
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"math"
+	"sort"
+)
+
+const (
+	PlayfieldWidth = 512.0
+
+	rngSeed = 1337
+
+	// Catcher's dash speed in osu!pixels per millisecond, walking is half as fast
+	BaseDashSpeed = 1.0
+	BaseWalkSpeed = 0.5
+)
+
+type ObjectType int64
+
+const (
+	Fruit = ObjectType(iota)
+	Droplet
+	TinyDroplet
+	Banana
+)
+
+// Object is a single fruit, droplet or banana generated from an osu!standard object
+type Object struct {
+	Source objects.IHitObject
+
+	Index int
+	Type  ObjectType
+
+	// Index of a fruit or banana in the beatmap, decides which fruit is drawn
+	VisualIndex int
+
+	StartTime int64
+
+	// Original position and the one after applying osu!stable's random offsets
+	OriginalX float64
+	X         float64
+
+	// Next object that can be reached only by hyperdashing from this one
+	HyperDashTarget *Object
+
+	// How much further the catcher could move and still reach the next object
+	DistanceToHyperDash float64
+
+	// Index of slider's head, repeat or tail a juice stream fruit was placed on
+	EdgeIndex int
+}
+
+// IsPalpable returns true for objects that are judged with combo
+func (object *Object) IsPalpable() bool {
+	return object.Type == Fruit || object.Type == Droplet
+}
+
+// GetCatcherScale returns catcher scale for a given circle size, 1.0 being CS 5
+func GetCatcherScale(cs float64) float64 {
+	return 1.0 - 0.7*(cs-5)/5
+}
+
+// GetCatchWidth returns width of the area in which catcher catches objects, in osu!pixels
+func GetCatchWidth(cs float64) float64 {
+	return 106.75 * math.Abs(GetCatcherScale(cs)) * 0.8
+}
+
+// GetModifiedCS returns circle size after Hard Rock and Easy are applied
+func GetModifiedCS(cs float64, mods difficulty.Modifier) float64 {
+	if mods.Active(difficulty.HardRock) {
+		return math.Min(cs*1.3, 10)
+	} else if mods.Active(difficulty.Easy) {
+		return cs / 2
+	}
+
+	return cs
+}
+
+func convertObjects(beatMap *beatmap.BeatMap, mods difficulty.Modifier) (converted []*Object) {
+	visualIndex := 0
+
+	add := func(source objects.IHitObject, oType ObjectType, time float64, x float32) *Object {
+		object := &Object{
+			Source:    source,
+			Type:      oType,
+			StartTime: int64(time),
+			OriginalX: float64(bmath.ClampF32(x, 0, PlayfieldWidth)),
+		}
+
+		object.X = object.OriginalX
+
+		if oType == Fruit || oType == Banana {
+			object.VisualIndex = visualIndex
+			visualIndex++
+		}
+
+		converted = append(converted, object)
+
+		return object
+	}
+
+	rng := newLegacyRandom(rngSeed)
+
+	lastPosition := math.NaN()
+	lastStartTime := 0.0
+
+	for _, o := range beatMap.HitObjects {
+		switch o.GetType() {
+		case objects.CIRCLE:
+			fruit := add(o, Fruit, o.GetStartTime(), o.GetStartPosition().X)
+
+			if mods.Active(difficulty.HardRock) {
+				applyHardRockOffset(fruit, &lastPosition, &lastStartTime, rng)
+			}
+		case objects.SLIDER:
+			start := len(converted)
+
+			generateJuiceStream(o.(*objects.Slider), beatMap.Timings, add)
+
+			lastPosition = float64(o.(*objects.Slider).GetCurve().PointAt(1).X)
+			lastStartTime = o.GetStartTime()
+
+			for _, nested := range converted[start:] {
+				switch nested.Type {
+				case TinyDroplet:
+					nested.X = bmath.ClampF64(nested.OriginalX+float64(rng.nextRange(-20, 20)), 0, PlayfieldWidth)
+				case Droplet:
+					rng.next() // osu!stable retrieved a random droplet rotation
+				}
+			}
+		case objects.SPINNER:
+			spacing := o.GetEndTime() - o.GetStartTime()
+			for spacing > 100 {
+				spacing /= 2
+			}
+
+			if spacing <= 0 {
+				continue
+			}
+
+			for time := o.GetStartTime(); time <= o.GetEndTime(); time += spacing {
+				banana := add(o, Banana, time, 0)
+				banana.OriginalX = rng.nextDouble() * PlayfieldWidth
+				banana.X = banana.OriginalX
+
+				rng.next() // osu!stable retrieved a random banana type
+				rng.next() // osu!stable retrieved a random banana rotation
+				rng.next() // osu!stable retrieved a random banana colour
+			}
+		}
+	}
+
+	sort.SliceStable(converted, func(i, j int) bool {
+		return converted[i].StartTime < converted[j].StartTime
+	})
+
+	for i, object := range converted {
+		object.Index = i
+	}
+
+	initHyperDash(converted, GetCatchWidth(GetModifiedCS(beatMap.Diff.GetCS(), mods)))
+
+	return
+}
+
+// generateJuiceStream places fruits on slider's head, repeats and tail, droplets on its ticks and tiny droplets between them
+func generateJuiceStream(slider *objects.Slider, timings *objects.Timings, add func(source objects.IHitObject, oType ObjectType, time float64, x float32) *Object) {
+	type event struct {
+		oType    ObjectType
+		time     float64
+		progress float64
+	}
+
+	path := slider.GetCurve()
+	length := float64(path.GetLength())
+
+	point := timings.GetPoint(slider.GetStartTime())
+
+	velocity := timings.GetVelocity(point) / 1000
+	tickDistance := math.Min(timings.GetTickDistance(point), length)
+	minDistanceFromEnd := velocity * 10
+
+	spans := bmath.MaxI64(slider.GetRepeats(), 1)
+	spanDuration := (slider.GetEndTime() - slider.GetStartTime()) / float64(spans)
+
+	events := []event{{Fruit, slider.GetStartTime(), 0}}
+
+	for span := int64(0); span < spans; span++ {
+		spanStart := slider.GetStartTime() + float64(span)*spanDuration
+		reversed := span%2 == 1
+
+		var ticks []event
+
+		if tickDistance > 0 {
+			for d := tickDistance; d <= length; d += tickDistance {
+				if d >= length-minDistanceFromEnd {
+					break
+				}
+
+				progress := d / length
+
+				timeProgress := progress
+				if reversed {
+					timeProgress = 1 - progress
+				}
+
+				ticks = append(ticks, event{Droplet, spanStart + timeProgress*spanDuration, progress})
+			}
+		}
+
+		if reversed {
+			for i, j := 0, len(ticks)-1; i < j; i, j = i+1, j-1 {
+				ticks[i], ticks[j] = ticks[j], ticks[i]
+			}
+		}
+
+		events = append(events, ticks...)
+
+		if span < spans-1 {
+			events = append(events, event{Fruit, spanStart + spanDuration, float64((span + 1) % 2)})
+		}
+	}
+
+	events = append(events, event{Fruit, slider.GetEndTime(), float64(spans % 2)})
+
+	edge := 0
+
+	for i, e := range events {
+		if i > 0 {
+			last := events[i-1]
+
+			sinceLastTick := float64(int64(e.time) - int64(last.time))
+
+			if sinceLastTick > 80 {
+				timeBetweenTiny := sinceLastTick
+				for timeBetweenTiny > 100 {
+					timeBetweenTiny /= 2
+				}
+
+				for t := timeBetweenTiny; t < sinceLastTick; t += timeBetweenTiny {
+					progress := last.progress + (t/sinceLastTick)*(e.progress-last.progress)
+					add(slider, TinyDroplet, t+last.time, path.PointAt(float32(progress)).X)
+				}
+			}
+		}
+
+		object := add(slider, e.oType, e.time, path.PointAt(float32(e.progress)).X)
+
+		if e.oType == Fruit {
+			object.EdgeIndex = edge
+			edge++
+		}
+	}
+}
+
+func applyHardRockOffset(object *Object, lastPosition, lastStartTime *float64, rng *legacyRandom) {
+	position := object.OriginalX
+	startTime := float64(object.StartTime)
+
+	if math.IsNaN(*lastPosition) {
+		*lastPosition = position
+		*lastStartTime = startTime
+
+		return
+	}
+
+	positionDiff := position - *lastPosition
+	timeDiff := float64(int64(startTime - *lastStartTime))
+
+	if timeDiff > 1000 {
+		*lastPosition = position
+		*lastStartTime = startTime
+
+		return
+	}
+
+	if positionDiff == 0 {
+		right := rng.nextBool()
+		offset := math.Min(20, float64(rng.nextRange(0, math.Max(0, timeDiff/4))))
+
+		if right {
+			if position+offset <= PlayfieldWidth {
+				position += offset
+			} else {
+				position -= offset
+			}
+		} else {
+			if position-offset >= 0 {
+				position -= offset
+			} else {
+				position += offset
+			}
+		}
+
+		object.X = position
+
+		return
+	}
+
+	if math.Abs(positionDiff) < timeDiff/3 {
+		if positionDiff > 0 {
+			if position+positionDiff < PlayfieldWidth {
+				position += positionDiff
+			}
+		} else if position+positionDiff > 0 {
+			position += positionDiff
+		}
+	}
+
+	object.X = position
+
+	*lastPosition = position
+	*lastStartTime = startTime
+}
+
+// initHyperDash marks fruits and droplets after which the catcher can't reach the next one even when dashing
+func initHyperDash(converted []*Object, catchWidth float64) {
+	var palpable []*Object
+
+	for _, object := range converted {
+		if object.IsPalpable() {
+			palpable = append(palpable, object)
+		}
+	}
+
+	halfCatcherWidth := catchWidth / 2 / 0.8
+
+	lastDirection := 0
+	lastExcess := halfCatcherWidth
+
+	for i := 0; i < len(palpable)-1; i++ {
+		current, next := palpable[i], palpable[i+1]
+
+		direction := -1
+		if next.X > current.X {
+			direction = 1
+		}
+
+		timeToNext := float64(next.StartTime-current.StartTime) - 1000.0/60/4
+
+		excess := halfCatcherWidth
+		if lastDirection == direction {
+			excess = lastExcess
+		}
+
+		distanceToHyper := timeToNext*BaseDashSpeed - (math.Abs(next.X-current.X) - excess)
+
+		if distanceToHyper < 0 {
+			current.HyperDashTarget = next
+			lastExcess = halfCatcherWidth
+		} else {
+			current.DistanceToHyperDash = distanceToHyper
+			lastExcess = bmath.ClampF64(distanceToHyper, 0, halfCatcherWidth)
+		}
+
+		lastDirection = direction
+	}
+}
@@ -0,0 +1,65 @@
+package catch
+
+type HitResult int64
+
+const (
+	Miss = HitResult(iota) // missed fruit or droplet
+	TinyMiss
+	BananaMiss
+	TinyHit
+	DropletHit
+	FruitHit
+	BananaHit
+)
+
+var HitResultsText = []string{"Miss", "Miss", "Miss", "10", "100", "300", "1000"}
+
+// ScoreValue is the ScoreV1 value of a result before combo multiplier is applied
+func (result HitResult) ScoreValue() int64 {
+	switch result {
+	case FruitHit:
+		return 300
+	case DropletHit:
+		return 100
+	case TinyHit:
+		return 10
+	case BananaHit:
+		return 1000
+	}
+
+	return 0
+}
+
+// IsHit returns true if the object was caught
+func (result HitResult) IsHit() bool {
+	return result >= TinyHit
+}
+
+// AffectsCombo returns true for results of fruits and droplets
+func (result HitResult) AffectsCombo() bool {
+	return result == Miss || result == DropletHit || result == FruitHit
+}
+
+// AffectsAccuracy returns true for results of everything except bananas
+func (result HitResult) AffectsAccuracy() bool {
+	return result != BananaHit && result != BananaMiss
+}
+
+func (result HitResult) healthChange() float64 {
+	switch result {
+	case FruitHit:
+		return 0.02
+	case DropletHit:
+		return 0.01
+	case TinyHit:
+		return 0.002
+	case Miss:
+		return -0.08
+	}
+
+	return 0
+}
+
+func (result HitResult) String() string {
+	return HitResultsText[result]
+}
@@ -0,0 +1,55 @@
+package catch
+
+// legacyRandom is the xorshift generator osu!stable uses to place objects, it has to produce exactly the same sequence
+type legacyRandom struct {
+	x, y, z, w uint32
+
+	bitBuffer uint32
+	bitIndex  int
+}
+
+func newLegacyRandom(seed int32) *legacyRandom {
+	return &legacyRandom{
+		x:        uint32(seed),
+		y:        842502087,
+		z:        3579807591,
+		w:        273326509,
+		bitIndex: 32,
+	}
+}
+
+func (r *legacyRandom) nextUInt() uint32 {
+	t := r.x ^ (r.x << 11)
+
+	r.x, r.y, r.z = r.y, r.z, r.w
+	r.w = r.w ^ (r.w >> 19) ^ t ^ (t >> 8)
+
+	return r.w
+}
+
+func (r *legacyRandom) next() int32 {
+	return int32(0x7FFFFFFF & r.nextUInt())
+}
+
+func (r *legacyRandom) nextDouble() float64 {
+	return 4.6566128730773926e-10 * float64(r.next())
+}
+
+// nextRange returns a number in [lower, upper)
+func (r *legacyRandom) nextRange(lower, upper float64) int32 {
+	return int32(lower + r.nextDouble()*(upper-lower))
+}
+
+func (r *legacyRandom) nextBool() bool {
+	if r.bitIndex == 32 {
+		r.bitBuffer = r.nextUInt()
+		r.bitIndex = 1
+
+		return r.bitBuffer&1 == 1
+	}
+
+	r.bitIndex++
+	r.bitBuffer >>= 1
+
+	return r.bitBuffer&1 == 1
+}
@@ -0,0 +1,355 @@
+package catch
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"log"
+	"math"
+)
+
+type ObjectState int64
+
+const (
+	Pending = ObjectState(iota)
+	Caught
+	Missed
+)
+
+// CatcherState is the state of a player's catcher at the last update
+type CatcherState struct {
+	X float64
+
+	Dashing      bool
+	HyperDashing bool
+	FacingLeft   bool
+}
+
+type player struct {
+	cursor *graphics.Cursor
+	mods   difficulty.Modifier
+
+	catchWidth    float64
+	modMultiplier float64
+
+	states []ObjectState
+	next   int
+
+	lastTime int64
+	catcher  CatcherState
+
+	hyperDashTarget *Object
+
+	score    int64
+	combo    int64
+	maxCombo int64
+
+	results  [7]int64
+	accuracy float64
+	hp       float64
+	grade    osu.Grade
+}
+
+type CatchRuleSet struct {
+	beatMap *beatmap.BeatMap
+
+	objects []*Object
+
+	scoreMultiplier float64
+
+	players map[*graphics.Cursor]*player
+	order   []*player
+
+	ended bool
+
+	hitListener func(cursor *graphics.Cursor, time int64, object *Object, result HitResult)
+}
+
+func NewCatchRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []difficulty.Modifier) *CatchRuleSet {
+	log.Println("Creating osu!catch ruleset...")
+
+	ruleset := new(CatchRuleSet)
+	ruleset.beatMap = beatMap
+
+	// Random offsets depend on Hard Rock so every player has to catch the same objects
+	ruleset.objects = convertObjects(beatMap, mods[0])
+
+	counts := make(map[ObjectType]int)
+	for _, o := range ruleset.objects {
+		counts[o.Type]++
+	}
+
+	log.Println(fmt.Sprintf("Fruits: %d, droplets: %d, tiny droplets: %d, bananas: %d", counts[Fruit], counts[Droplet], counts[TinyDroplet], counts[Banana]))
+
+	ruleset.scoreMultiplier = calculateScoreMultiplier(beatMap)
+
+	ruleset.players = make(map[*graphics.Cursor]*player)
+
+	for i, cursor := range cursors {
+		p := &player{
+			cursor:        cursor,
+			mods:          mods[i],
+			catchWidth:    GetCatchWidth(GetModifiedCS(beatMap.Diff.GetCS(), mods[i])),
+			modMultiplier: mods[i].GetScoreMultiplier(),
+			states:        make([]ObjectState, len(ruleset.objects)),
+			lastTime:      math.MinInt64,
+			catcher:       CatcherState{X: PlayfieldWidth / 2},
+			accuracy:      100,
+			hp:            1,
+			grade:         osu.NONE,
+		}
+
+		ruleset.players[cursor] = p
+		ruleset.order = append(ruleset.order, p)
+	}
+
+	return ruleset
+}
+
+// calculateScoreMultiplier returns osu!stable's difficulty multiplier used in ScoreV1 combo bonus
+func calculateScoreMultiplier(beatMap *beatmap.BeatMap) float64 {
+	if len(beatMap.HitObjects) == 0 {
+		return 0
+	}
+
+	pauses := int64(0)
+	for _, p := range beatMap.Pauses {
+		pauses += int64(p.GetEndTime() - p.GetStartTime())
+	}
+
+	drainTime := math.Max(1, float64((int64(beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime())-int64(beatMap.HitObjects[0].GetStartTime())-pauses)/1000))
+
+	density := bmath.ClampF64(float64(len(beatMap.HitObjects))/drainTime*8, 0, 16)
+
+	return math.RoundToEven((beatMap.Diff.GetHPDrain() + beatMap.Diff.GetOD() + beatMap.Diff.GetCS() + density) / 38 * 5)
+}
+
+// UpdatePosition moves the catcher to x at the given time, objects passed in the meantime are judged against catcher's position interpolated between updates
+func (set *CatchRuleSet) UpdatePosition(cursor *graphics.Cursor, time int64, x float64, dash bool) {
+	p := set.players[cursor]
+	if p == nil || time < p.lastTime {
+		return
+	}
+
+	x = bmath.ClampF64(x, 0, PlayfieldWidth)
+
+	lastX := p.catcher.X
+
+	for p.next < len(set.objects) && set.objects[p.next].StartTime <= time {
+		object := set.objects[p.next]
+
+		catcherX := x
+		if p.lastTime != math.MinInt64 && time > p.lastTime {
+			catcherX = lastX + (x-lastX)*float64(object.StartTime-p.lastTime)/float64(time-p.lastTime)
+		}
+
+		set.judge(p, object, catcherX)
+	}
+
+	if x < lastX {
+		p.catcher.FacingLeft = true
+	} else if x > lastX {
+		p.catcher.FacingLeft = false
+	}
+
+	if target := p.hyperDashTarget; target != nil && (time > target.StartTime || (x-target.X)*(lastX-target.X) <= 0) {
+		p.hyperDashTarget = nil
+	}
+
+	p.catcher.X = x
+	p.catcher.Dashing = dash
+	p.catcher.HyperDashing = p.hyperDashTarget != nil
+
+	p.lastTime = time
+}
+
+func (set *CatchRuleSet) judge(p *player, object *Object, catcherX float64) {
+	p.next++
+
+	caught := math.Abs(catcherX-object.X) <= p.catchWidth/2
+
+	var result HitResult
+
+	switch object.Type {
+	case Fruit:
+		result = Miss
+		if caught {
+			result = FruitHit
+		}
+	case Droplet:
+		result = Miss
+		if caught {
+			result = DropletHit
+		}
+	case TinyDroplet:
+		result = TinyMiss
+		if caught {
+			result = TinyHit
+		}
+	case Banana:
+		result = BananaMiss
+		if caught {
+			result = BananaHit
+		}
+	}
+
+	if caught {
+		p.states[object.Index] = Caught
+	} else {
+		p.states[object.Index] = Missed
+	}
+
+	if object.IsPalpable() {
+		p.hyperDashTarget = nil
+
+		if caught {
+			p.hyperDashTarget = object.HyperDashTarget
+		}
+	}
+
+	set.applyResult(p, object, result)
+}
+
+func (set *CatchRuleSet) applyResult(p *player, object *Object, result HitResult) {
+	p.results[result]++
+
+	increase := result.ScoreValue()
+
+	if result.AffectsCombo() && result != Miss {
+		combo := bmath.MaxI64(p.combo-1, 0)
+		p.score += increase + int64(float64(increase)*float64(combo)*set.scoreMultiplier*p.modMultiplier/25.0)
+	} else {
+		p.score += increase
+	}
+
+	if result.AffectsCombo() {
+		if result == Miss {
+			p.combo = 0
+		} else {
+			p.combo++
+			p.maxCombo = bmath.MaxI64(p.maxCombo, p.combo)
+		}
+	}
+
+	caught, total := int64(0), int64(0)
+
+	for r, count := range p.results {
+		if HitResult(r).AffectsAccuracy() {
+			total += count
+
+			if HitResult(r).IsHit() {
+				caught += count
+			}
+		}
+	}
+
+	if total > 0 {
+		p.accuracy = 100 * float64(caught) / float64(total)
+	}
+
+	change := result.healthChange()
+	if change < 0 {
+		change *= difficulty.DifficultyRate(set.beatMap.Diff.GetHPDrain(), 0.5, 1, 1.5)
+	}
+
+	p.hp = bmath.ClampF64(p.hp+change, 0, 1)
+
+	p.grade = calculateGrade(p.accuracy, p.mods)
+
+	if set.hitListener != nil {
+		set.hitListener(p.cursor, object.StartTime, object, result)
+	}
+}
+
+func calculateGrade(accuracy float64, mods difficulty.Modifier) osu.Grade {
+	silver := mods&(difficulty.Hidden|difficulty.Flashlight) > 0
+
+	switch {
+	case accuracy >= 100 && silver:
+		return osu.SSH
+	case accuracy >= 100:
+		return osu.SS
+	case accuracy > 98 && silver:
+		return osu.SH
+	case accuracy > 98:
+		return osu.S
+	case accuracy > 94:
+		return osu.A
+	case accuracy > 90:
+		return osu.B
+	case accuracy > 85:
+		return osu.C
+	}
+
+	return osu.D
+}
+
+// Update checks whether all objects have been judged, judgements themselves happen in UpdatePosition
+func (set *CatchRuleSet) Update(_ int64) {
+	ended := true
+
+	for _, p := range set.order {
+		if p.next < len(set.objects) {
+			ended = false
+		}
+	}
+
+	set.ended = ended
+}
+
+// SetListener sets a function called after every judgement
+func (set *CatchRuleSet) SetListener(listener func(cursor *graphics.Cursor, time int64, object *Object, result HitResult)) {
+	set.hitListener = listener
+}
+
+func (set *CatchRuleSet) GetObjects() []*Object {
+	return set.objects
+}
+
+func (set *CatchRuleSet) GetObjectState(cursor *graphics.Cursor, object *Object) ObjectState {
+	return set.players[cursor].states[object.Index]
+}
+
+func (set *CatchRuleSet) GetCatcher(cursor *graphics.Cursor) CatcherState {
+	return set.players[cursor].catcher
+}
+
+// GetCatchWidth returns width of player's catching area in osu!pixels
+func (set *CatchRuleSet) GetCatchWidth(cursor *graphics.Cursor) float64 {
+	return set.players[cursor].catchWidth
+}
+
+func (set *CatchRuleSet) GetResults(cursor *graphics.Cursor) (float64, int64, int64, osu.Grade) {
+	p := set.players[cursor]
+	return p.accuracy, p.maxCombo, p.score, p.grade
+}
+
+// GetHits returns amounts of caught fruits, droplets and tiny droplets, missed tiny droplets, misses and caught bananas
+func (set *CatchRuleSet) GetHits(cursor *graphics.Cursor) (int64, int64, int64, int64, int64, int64) {
+	p := set.players[cursor]
+	return p.results[FruitHit], p.results[DropletHit], p.results[TinyHit], p.results[TinyMiss], p.results[Miss], p.results[BananaHit]
+}
+
+func (set *CatchRuleSet) GetCombo(cursor *graphics.Cursor) int64 {
+	return set.players[cursor].combo
+}
+
+func (set *CatchRuleSet) GetHP(cursor *graphics.Cursor) float64 {
+	return set.players[cursor].hp
+}
+
+func (set *CatchRuleSet) GetMods(cursor *graphics.Cursor) difficulty.Modifier {
+	return set.players[cursor].mods
+}
+
+// IsEnded returns true when all objects have been judged
+func (set *CatchRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+func (set *CatchRuleSet) GetBeatMap() *beatmap.BeatMap {
+	return set.beatMap
+}
@@ -0,0 +1,94 @@
+package mania
+
+type HitResult int64
+
+const (
+	Miss = HitResult(iota)
+	Hit50
+	Hit100
+	Hit200
+	Hit300
+	Hit320
+)
+
+var HitResultsText = []string{"Miss", "50", "100", "200", "300", "320"}
+
+// ScoreValue is the value used in ScoreV1 base score, 320s are worth more than 300s
+func (result HitResult) ScoreValue() int64 {
+	switch result {
+	case Hit320:
+		return 320
+	case Hit300:
+		return 300
+	case Hit200:
+		return 200
+	case Hit100:
+		return 100
+	case Hit50:
+		return 50
+	}
+
+	return 0
+}
+
+// AccuracyValue is the value used in accuracy calculation, 320s and 300s are equal
+func (result HitResult) AccuracyValue() int64 {
+	if result == Hit320 {
+		return 300
+	}
+
+	return result.ScoreValue()
+}
+
+func (result HitResult) bonusValue() float64 {
+	switch result {
+	case Hit320, Hit300:
+		return 32
+	case Hit200:
+		return 16
+	case Hit100:
+		return 8
+	case Hit50:
+		return 4
+	}
+
+	return 0
+}
+
+func (result HitResult) bonusChange() float64 {
+	switch result {
+	case Hit320:
+		return 2
+	case Hit300:
+		return 1
+	case Hit200:
+		return -8
+	case Hit100:
+		return -24
+	case Hit50:
+		return -44
+	}
+
+	return -100
+}
+
+func (result HitResult) healthChange() float64 {
+	switch result {
+	case Hit320:
+		return 0.011
+	case Hit300:
+		return 0.009
+	case Hit200:
+		return 0.004
+	case Hit100:
+		return 0
+	case Hit50:
+		return -0.024
+	}
+
+	return -0.054
+}
+
+func (result HitResult) String() string {
+	return HitResultsText[result]
+}
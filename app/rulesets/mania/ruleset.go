@@ -0,0 +1,460 @@
+package mania
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"log"
+	"math"
+)
+
+const MaxScore = 1000000.0
+
+type NoteState int64
+
+const (
+	Pending = NoteState(iota)
+	Holding
+	Hit
+	Missed
+)
+
+// Note is a single mania note or hold note placed in a column
+type Note struct {
+	Object objects.IHitObject
+
+	Index  int
+	Column int
+
+	StartTime int64
+	EndTime   int64
+	Hold      bool
+}
+
+type column struct {
+	next       int
+	holding    *Note
+	headResult HitResult
+	pressed    bool
+}
+
+type player struct {
+	cursor *graphics.Cursor
+	mods   difficulty.Modifier
+
+	// 320, 300, 200, 100, 50 and miss windows
+	windows [6]float64
+
+	columns []*column
+	states  []NoteState
+
+	keys uint32
+
+	baseScore  float64
+	bonusScore float64
+	bonus      float64
+	score      int64
+
+	combo    int64
+	maxCombo int64
+
+	hits     [6]int64
+	judged   int64
+	accuracy float64
+	hp       float64
+	grade    osu.Grade
+}
+
+type ManiaRuleSet struct {
+	beatMap *beatmap.BeatMap
+	keys    int
+
+	notes   []*Note
+	columns [][]*Note
+
+	totalJudgements int64
+
+	players map[*graphics.Cursor]*player
+	order   []*player
+
+	ended bool
+
+	hitListener func(cursor *graphics.Cursor, time int64, note *Note, result HitResult, head bool)
+}
+
+// GetKeyCount returns the amount of columns of a mania beatmap
+func GetKeyCount(beatMap *beatmap.BeatMap) int {
+	return bmath.ClampI(int(math.Round(beatMap.Diff.GetCS())), 1, 18)
+}
+
+func NewManiaRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []difficulty.Modifier) *ManiaRuleSet {
+	log.Println("Creating osu!mania ruleset...")
+
+	ruleset := new(ManiaRuleSet)
+	ruleset.beatMap = beatMap
+	ruleset.keys = GetKeyCount(beatMap)
+	ruleset.columns = make([][]*Note, ruleset.keys)
+
+	for _, o := range beatMap.HitObjects {
+		if o.GetType() == objects.SPINNER || o.GetType() == objects.SLIDER {
+			continue
+		}
+
+		note := &Note{
+			Object:    o,
+			Index:     len(ruleset.notes),
+			Column:    bmath.ClampI(int(float64(o.GetStartPosition().X)*float64(ruleset.keys)/512), 0, ruleset.keys-1),
+			StartTime: int64(o.GetStartTime()),
+			EndTime:   int64(o.GetEndTime()),
+			Hold:      o.GetType() == objects.LONGNOTE,
+		}
+
+		ruleset.notes = append(ruleset.notes, note)
+		ruleset.columns[note.Column] = append(ruleset.columns[note.Column], note)
+
+		ruleset.totalJudgements++
+
+		if note.Hold {
+			ruleset.totalJudgements++
+		}
+	}
+
+	log.Println(fmt.Sprintf("Keys: %d, notes: %d, judgements: %d", ruleset.keys, len(ruleset.notes), ruleset.totalJudgements))
+
+	ruleset.players = make(map[*graphics.Cursor]*player)
+
+	for i, cursor := range cursors {
+		p := &player{
+			cursor:   cursor,
+			mods:     mods[i],
+			columns:  make([]*column, ruleset.keys),
+			states:   make([]NoteState, len(ruleset.notes)),
+			bonus:    100,
+			accuracy: 100,
+			hp:       1,
+			grade:    osu.NONE,
+		}
+
+		for j := range p.columns {
+			p.columns[j] = new(column)
+		}
+
+		p.windows = calculateWindows(beatMap.Diff.GetOD(), mods[i])
+
+		ruleset.players[cursor] = p
+		ruleset.order = append(ruleset.order, p)
+	}
+
+	return ruleset
+}
+
+func calculateWindows(od float64, mods difficulty.Modifier) (windows [6]float64) {
+	windows = [6]float64{16, 64 - 3*od, 97 - 3*od, 127 - 3*od, 151 - 3*od, 188 - 3*od}
+
+	scale := 1.0
+
+	if mods.Active(difficulty.HardRock) {
+		scale /= 1.4
+	} else if mods.Active(difficulty.Easy) {
+		scale *= 1.4
+	}
+
+	for i := range windows {
+		windows[i] = math.Floor(windows[i] * scale)
+	}
+
+	return
+}
+
+func (p *player) judge(offset float64) HitResult {
+	offset = math.Abs(offset)
+
+	for i, result := range []HitResult{Hit320, Hit300, Hit200, Hit100, Hit50} {
+		if offset <= p.windows[i] {
+			return result
+		}
+	}
+
+	return Miss
+}
+
+// UpdateKeys processes a change of pressed columns at the given time, bit i of keys is set when column i is held
+func (set *ManiaRuleSet) UpdateKeys(cursor *graphics.Cursor, time int64, keys uint32) {
+	p := set.players[cursor]
+	if p == nil {
+		return
+	}
+
+	set.updatePlayer(p, time)
+
+	for c, col := range p.columns {
+		pressed := keys&(1<<uint(c)) > 0
+
+		if pressed == col.pressed {
+			continue
+		}
+
+		col.pressed = pressed
+
+		if pressed {
+			set.press(p, c, time)
+		} else {
+			set.release(p, c, time)
+		}
+	}
+
+	p.keys = keys
+}
+
+func (set *ManiaRuleSet) press(p *player, c int, time int64) {
+	col := p.columns[c]
+
+	if col.holding != nil || col.next >= len(set.columns[c]) {
+		return
+	}
+
+	note := set.columns[c][col.next]
+
+	if float64(note.StartTime-time) > p.windows[5] {
+		return
+	}
+
+	result := p.judge(float64(time - note.StartTime))
+
+	set.applyResult(p, note, time, result, true)
+
+	if note.Hold && result != Miss {
+		col.holding = note
+		col.headResult = result
+		p.states[note.Index] = Holding
+	} else {
+		if note.Hold {
+			set.applyResult(p, note, time, Miss, false)
+		}
+
+		set.finish(p, note, result != Miss)
+	}
+}
+
+func (set *ManiaRuleSet) release(p *player, c int, time int64) {
+	col := p.columns[c]
+
+	note := col.holding
+	if note == nil {
+		return
+	}
+
+	// Releases are more lenient than presses, holding until the end is handled in updatePlayer
+	result := p.judge(float64(time-note.EndTime) / 1.5)
+
+	set.applyResult(p, note, time, result, false)
+	set.finish(p, note, result != Miss)
+}
+
+func (set *ManiaRuleSet) finish(p *player, note *Note, hit bool) {
+	col := p.columns[note.Column]
+
+	if hit {
+		p.states[note.Index] = Hit
+	} else {
+		p.states[note.Index] = Missed
+	}
+
+	col.holding = nil
+	col.next++
+}
+
+func (set *ManiaRuleSet) updatePlayer(p *player, time int64) {
+	for c, col := range p.columns {
+		for {
+			if note := col.holding; note != nil {
+				if col.pressed && time >= note.EndTime {
+					set.applyResult(p, note, note.EndTime, col.headResult, false)
+					set.finish(p, note, true)
+
+					continue
+				}
+
+				break
+			}
+
+			if col.next >= len(set.columns[c]) {
+				break
+			}
+
+			note := set.columns[c][col.next]
+
+			if float64(time-note.StartTime) <= p.windows[5] {
+				break
+			}
+
+			missTime := note.StartTime + int64(p.windows[5])
+
+			set.applyResult(p, note, missTime, Miss, true)
+
+			if note.Hold {
+				set.applyResult(p, note, missTime, Miss, false)
+			}
+
+			set.finish(p, note, false)
+		}
+	}
+}
+
+func (set *ManiaRuleSet) applyResult(p *player, note *Note, time int64, result HitResult, head bool) {
+	p.hits[result]++
+	p.judged++
+
+	if result == Miss {
+		p.combo = 0
+	} else {
+		p.combo++
+		p.maxCombo = bmath.MaxI64(p.maxCombo, p.combo)
+	}
+
+	// osu!stable mania ScoreV1: half of the score comes from judgements, the other half from a bonus meter punishing bad hits
+	noteValue := MaxScore * scoreMultiplier(p.mods) * 0.5 / float64(bmath.MaxI64(set.totalJudgements, 1))
+
+	p.bonus = bmath.ClampF64(p.bonus+result.bonusChange(), 0, 100)
+
+	p.baseScore += noteValue * float64(result.ScoreValue()) / 320
+	p.bonusScore += noteValue * result.bonusValue() * math.Sqrt(p.bonus) / 320
+
+	p.score = int64(math.Round(p.baseScore + p.bonusScore))
+
+	accValue := int64(0)
+	for r, count := range p.hits {
+		accValue += HitResult(r).AccuracyValue() * count
+	}
+
+	p.accuracy = 100 * float64(accValue) / float64(p.judged*300)
+
+	change := result.healthChange()
+	if change < 0 {
+		change *= difficulty.DifficultyRate(set.beatMap.Diff.GetHPDrain(), 0.5, 1, 1.5)
+	}
+
+	p.hp = bmath.ClampF64(p.hp+change, 0, 1)
+
+	p.grade = calculateGrade(p.accuracy, p.mods)
+
+	if set.hitListener != nil {
+		set.hitListener(p.cursor, time, note, result, head)
+	}
+}
+
+func scoreMultiplier(mods difficulty.Modifier) float64 {
+	multiplier := 1.0
+
+	if mods.Active(difficulty.NoFail) {
+		multiplier *= 0.5
+	}
+
+	if mods.Active(difficulty.Easy) {
+		multiplier *= 0.5
+	}
+
+	if mods.Active(difficulty.HalfTime) {
+		multiplier *= 0.5
+	}
+
+	return multiplier
+}
+
+func calculateGrade(accuracy float64, mods difficulty.Modifier) osu.Grade {
+	silver := mods&(difficulty.Hidden|difficulty.Flashlight) > 0
+
+	switch {
+	case accuracy >= 100 && silver:
+		return osu.SSH
+	case accuracy >= 100:
+		return osu.SS
+	case accuracy > 95 && silver:
+		return osu.SH
+	case accuracy > 95:
+		return osu.S
+	case accuracy > 90:
+		return osu.A
+	case accuracy > 80:
+		return osu.B
+	case accuracy > 70:
+		return osu.C
+	}
+
+	return osu.D
+}
+
+// Update judges notes that were not pressed in time and hold notes held until their end
+func (set *ManiaRuleSet) Update(time int64) {
+	ended := true
+
+	for _, p := range set.order {
+		set.updatePlayer(p, time)
+
+		for c, col := range p.columns {
+			if col.holding != nil || col.next < len(set.columns[c]) {
+				ended = false
+			}
+		}
+	}
+
+	set.ended = ended
+}
+
+// SetListener sets a function called after every judgement, head is false for judgements of hold note ends
+func (set *ManiaRuleSet) SetListener(listener func(cursor *graphics.Cursor, time int64, note *Note, result HitResult, head bool)) {
+	set.hitListener = listener
+}
+
+func (set *ManiaRuleSet) GetKeyCount() int {
+	return set.keys
+}
+
+func (set *ManiaRuleSet) GetNotes() []*Note {
+	return set.notes
+}
+
+func (set *ManiaRuleSet) GetNoteState(cursor *graphics.Cursor, note *Note) NoteState {
+	return set.players[cursor].states[note.Index]
+}
+
+// IsPressed returns whether the column is currently held by the player
+func (set *ManiaRuleSet) IsPressed(cursor *graphics.Cursor, column int) bool {
+	return set.players[cursor].columns[column].pressed
+}
+
+func (set *ManiaRuleSet) GetResults(cursor *graphics.Cursor) (float64, int64, int64, osu.Grade) {
+	p := set.players[cursor]
+	return p.accuracy, p.maxCombo, p.score, p.grade
+}
+
+// GetHits returns amounts of 320s, 300s, 200s, 100s, 50s and misses
+func (set *ManiaRuleSet) GetHits(cursor *graphics.Cursor) (int64, int64, int64, int64, int64, int64) {
+	p := set.players[cursor]
+	return p.hits[Hit320], p.hits[Hit300], p.hits[Hit200], p.hits[Hit100], p.hits[Hit50], p.hits[Miss]
+}
+
+func (set *ManiaRuleSet) GetCombo(cursor *graphics.Cursor) int64 {
+	return set.players[cursor].combo
+}
+
+func (set *ManiaRuleSet) GetHP(cursor *graphics.Cursor) float64 {
+	return set.players[cursor].hp
+}
+
+func (set *ManiaRuleSet) GetMods(cursor *graphics.Cursor) difficulty.Modifier {
+	return set.players[cursor].mods
+}
+
+// IsEnded returns true when all notes have been judged
+func (set *ManiaRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+func (set *ManiaRuleSet) GetBeatMap() *beatmap.BeatMap {
+	return set.beatMap
+}
@@ -65,28 +65,19 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 					player.rightCondE = false
 				}
 
-				hit := Miss
-
-				relative := int64(math.Abs(float64(time) - circle.hitCircle.GetEndTime()))
-				if relative < player.diff.Hit300 {
-					hit = Hit300
-				} else if relative < player.diff.Hit100 {
-					hit = Hit100
-				} else if relative < player.diff.Hit50 {
-					hit = Hit50
-				}
+				hit := circle.ruleSet.profile.JudgeHit(player.diff, math.Abs(float64(time)-circle.hitCircle.GetEndTime()))
 
 				if hit != Ignore {
 					combo := ComboResults.Increase
 					if hit == Miss {
 						combo = ComboResults.Reset
 					} else {
-						if len(circle.players) == 1 {
+						if circle.ruleSet.showsFeedback(len(circle.players)) {
 							circle.hitCircle.PlaySound()
 						}
 					}
 
-					if len(circle.players) == 1 {
+					if circle.ruleSet.showsFeedback(len(circle.players)) {
 						circle.hitCircle.Arm(hit != Miss, float64(time))
 					}
 
@@ -98,7 +89,7 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 				player.leftCondE = false
 				player.rightCondE = false
 
-				if action == Shake && len(circle.players) == 1 {
+				if action == Shake && circle.ruleSet.showsFeedback(len(circle.players)) {
 					circle.hitCircle.Shake(float64(time))
 				}
 			}
@@ -115,7 +106,7 @@ func (circle *Circle) UpdatePostFor(player *difficultyPlayer, time int64) bool {
 		position := circle.hitCircle.GetStackedPositionAtMod(float64(time), player.diff.Mods)
 		circle.ruleSet.SendResult(time, player.cursor, circle.hitCircle.GetID(), position.X, position.Y, Miss, false, ComboResults.Reset)
 
-		if len(circle.players) == 1 {
+		if circle.ruleSet.showsFeedback(len(circle.players)) {
 			circle.hitCircle.Arm(false, float64(time))
 		}
 
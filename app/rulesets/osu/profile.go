@@ -0,0 +1,263 @@
+package osu
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"math"
+	"strings"
+)
+
+// ScoringProfile defines how hits are judged and how score, accuracy and grade are calculated
+type ScoringProfile interface {
+	// JudgeHit returns the judgement of a circle clicked relative ms away from its time, Miss if it's outside of hit windows
+	JudgeHit(diff *difficulty.Difficulty, relative float64) HitResult
+
+	// SliderEndOffset returns how many ms before its real end slider end is checked
+	SliderEndOffset() int64
+
+	// SpinnerRequirement returns the amount of rotations needed to clear a spinner
+	SpinnerRequirement(diff *difficulty.Difficulty, duration float64) int64
+
+	// AddResult is called for every result before hit counts and combo are updated
+	AddResult(set *OsuRuleSet, subSet *subSet, result HitResult, raw bool)
+
+	// Update recalculates accuracy, score and grade after hit counts and combo were updated
+	Update(set *OsuRuleSet, subSet *subSet)
+
+	// NewInputHandling returns true if object ends of a replay made with given osu! version are judged only on replay frames, version is 0 for cursors not playing a replay
+	NewInputHandling(version int32) bool
+
+	// OldSpinnerScoring returns true if spinners of a replay made with given osu! version are scored the old way, version is 0 for cursors not playing a replay
+	OldSpinnerScoring(version int32) bool
+}
+
+// NewScoringProfile returns a profile by its name: "stable" or "lazer". Unknown names fall back to stable.
+func NewScoringProfile(name string, beatMap *beatmap.BeatMap) ScoringProfile {
+	if strings.ToLower(name) == "lazer" {
+		return newLazerProfile(beatMap)
+	}
+
+	return stableProfile{}
+}
+
+// stableProfile mirrors osu!stable, including ScoreV2 mod
+type stableProfile struct{}
+
+func (stableProfile) JudgeHit(diff *difficulty.Difficulty, relative float64) HitResult {
+	rel := int64(relative)
+
+	switch {
+	case rel < diff.Hit300:
+		return Hit300
+	case rel < diff.Hit100:
+		return Hit100
+	case rel < diff.Hit50:
+		return Hit50
+	}
+
+	return Miss
+}
+
+func (stableProfile) SliderEndOffset() int64 {
+	return 36
+}
+
+func (stableProfile) SpinnerRequirement(diff *difficulty.Difficulty, duration float64) int64 {
+	return int64(duration / 1000 * diff.SpinnerRatio)
+}
+
+func (stableProfile) AddResult(set *OsuRuleSet, subSet *subSet, result HitResult, raw bool) {
+	combo := bmath.MaxI64(subSet.combo-1, 0)
+
+	if subSet.scoreV2 {
+		if result&BaseHits > 0 {
+			subSet.comboPortion += comboPortionValue(result, subSet.combo)
+		} else if result&(SpinnerPoints|SpinnerBonus) > 0 {
+			subSet.bonusScore += result.ScoreValue()
+		}
+	} else if result != SliderMiss {
+		increase := result.ScoreValue()
+
+		if raw {
+			subSet.score += increase
+		} else {
+			subSet.score += increase + int64(float64(increase)*float64(combo)*set.scoreMultiplier*subSet.modMultiplier/25.0)
+		}
+	}
+}
+
+func (stableProfile) NewInputHandling(version int32) bool {
+	return version == 0 || version >= 20190506 // This was when slider scoring was changed, so *I think* replay handling as well: https://osu.ppy.sh/home/changelog/cuttingedge/20190506
+}
+
+func (stableProfile) OldSpinnerScoring(version int32) bool {
+	return version != 0 && version < 20190510 // This was when spinner scoring was changed: https://osu.ppy.sh/home/changelog/cuttingedge/20190510.2
+}
+
+func (stableProfile) Update(set *OsuRuleSet, subSet *subSet) {
+	if subSet.numObjects == 0 {
+		subSet.accuracy = 100
+	} else {
+		subSet.accuracy = 100 * float64(subSet.rawScore) / float64(subSet.numObjects*300)
+	}
+
+	if subSet.scoreV2 {
+		subSet.score = set.calculateScoreV2(subSet)
+	}
+
+	silver := subSet.player.diff.Mods&(difficulty.Hidden|difficulty.Flashlight) > 0
+
+	ratio := float64(subSet.hits[Hit300]) / float64(subSet.numObjects)
+
+	if subSet.hits[Hit300] == subSet.numObjects {
+		if silver {
+			subSet.grade = SSH
+		} else {
+			subSet.grade = SS
+		}
+	} else if ratio > 0.9 && float64(subSet.hits[Hit50])/float64(subSet.numObjects) < 0.01 && subSet.hits[Miss] == 0 {
+		if silver {
+			subSet.grade = SH
+		} else {
+			subSet.grade = S
+		}
+	} else if ratio > 0.8 && subSet.hits[Miss] == 0 || ratio > 0.9 {
+		subSet.grade = A
+	} else if ratio > 0.7 && subSet.hits[Miss] == 0 || ratio > 0.8 {
+		subSet.grade = B
+	} else if ratio > 0.6 {
+		subSet.grade = C
+	} else {
+		subSet.grade = D
+	}
+}
+
+const (
+	lazerMaxScore        = 1000000.0
+	lazerAccuracyPortion = 0.3
+	lazerComboPortion    = 0.7
+
+	lazerTickValue        = 30
+	lazerSpinnerTickValue = 10
+	lazerSpinnerBonus     = 50
+
+	// osu!lazer lowers spinner requirement to roughly match what stable really expects
+	lazerSpinnerFudge = 0.6
+)
+
+// lazerProfile approximates osu!lazer's standardised scoring. Slider heads, ticks, repeats and ends are worth 30 accuracy points,
+// score is a mix of accuracy and max combo and grades depend only on accuracy.
+type lazerProfile struct {
+	maxBaseValue int64
+	maxCombo     int64
+}
+
+func newLazerProfile(beatMap *beatmap.BeatMap) *lazerProfile {
+	profile := new(lazerProfile)
+
+	for _, o := range beatMap.HitObjects {
+		if s, ok := o.(*objects.Slider); ok {
+			profile.maxBaseValue += int64(len(s.ScorePoints)+1) * lazerTickValue
+			profile.maxCombo += int64(len(s.ScorePoints))
+		}
+
+		profile.maxBaseValue += 300
+		profile.maxCombo++
+	}
+
+	return profile
+}
+
+func (profile *lazerProfile) JudgeHit(diff *difficulty.Difficulty, relative float64) HitResult {
+	hit300, hit100, hit50 := diff.GetHitWindows()
+
+	switch {
+	case relative <= hit300:
+		return Hit300
+	case relative <= hit100:
+		return Hit100
+	case relative <= hit50:
+		return Hit50
+	}
+
+	return Miss
+}
+
+func (profile *lazerProfile) SliderEndOffset() int64 {
+	return 36
+}
+
+func (profile *lazerProfile) SpinnerRequirement(diff *difficulty.Difficulty, duration float64) int64 {
+	return int64(duration / 1000 * diff.SpinnerRatio * lazerSpinnerFudge)
+}
+
+func (profile *lazerProfile) AddResult(_ *OsuRuleSet, subSet *subSet, result HitResult, _ bool) {
+	switch {
+	case result&BaseHitsM > 0:
+		subSet.baseValue += result.ScoreValue()
+		subSet.maxBaseValue += 300
+	case result&(SliderStart|SliderPoint|SliderRepeat|SliderEnd) > 0:
+		subSet.baseValue += lazerTickValue
+		subSet.maxBaseValue += lazerTickValue
+	case result == SliderMiss:
+		subSet.maxBaseValue += lazerTickValue
+	case result == SpinnerPoints:
+		subSet.bonusScore += lazerSpinnerTickValue
+	case result == SpinnerBonus:
+		subSet.bonusScore += lazerSpinnerBonus
+	}
+}
+
+// NewInputHandling returns true, osu!lazer doesn't depend on the version replay was made with
+func (profile *lazerProfile) NewInputHandling(int32) bool {
+	return true
+}
+
+func (profile *lazerProfile) OldSpinnerScoring(int32) bool {
+	return false
+}
+
+func (profile *lazerProfile) Update(_ *OsuRuleSet, subSet *subSet) {
+	subSet.accuracy = 100
+
+	if subSet.maxBaseValue > 0 {
+		subSet.accuracy = 100 * float64(subSet.baseValue) / float64(subSet.maxBaseValue)
+	}
+
+	accuracyPart, comboPart := 0.0, 0.0
+
+	if profile.maxBaseValue > 0 {
+		accuracyPart = float64(subSet.baseValue) / float64(profile.maxBaseValue)
+	}
+
+	if profile.maxCombo > 0 {
+		comboPart = float64(subSet.maxCombo) / float64(profile.maxCombo)
+	}
+
+	multiplier := (subSet.player.diff.Mods &^ difficulty.ScoreV2).GetScoreMultiplier()
+
+	subSet.score = int64(math.Round(lazerMaxScore*(accuracyPart*lazerAccuracyPortion+comboPart*lazerComboPortion)*multiplier)) + subSet.bonusScore
+
+	silver := subSet.player.diff.Mods&(difficulty.Hidden|difficulty.Flashlight) > 0
+
+	switch {
+	case subSet.baseValue == subSet.maxBaseValue && silver:
+		subSet.grade = SSH
+	case subSet.baseValue == subSet.maxBaseValue:
+		subSet.grade = SS
+	case subSet.accuracy >= 95 && silver:
+		subSet.grade = SH
+	case subSet.accuracy >= 95:
+		subSet.grade = S
+	case subSet.accuracy >= 90:
+		subSet.grade = A
+	case subSet.accuracy >= 80:
+		subSet.grade = B
+	case subSet.accuracy >= 70:
+		subSet.grade = C
+	default:
+		subSet.grade = D
+	}
+}
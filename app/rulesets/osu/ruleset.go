@@ -74,6 +74,7 @@ type HitObject interface {
 type difficultyPlayer struct {
 	cursor          *graphics.Cursor
 	diff            *difficulty.Difficulty
+	oldSpinners     bool
 	DoubleClick     bool
 	alreadyStolen   bool
 	buttons         buttonState
@@ -105,6 +106,11 @@ type subSet struct {
 	gekiCount     int64
 	katuCount     int64
 	recoveries    int
+	scoreV2       bool
+	comboPortion  float64
+	bonusScore    int64
+	baseValue     int64
+	maxBaseValue  int64
 }
 
 type MapTo struct {
@@ -117,10 +123,16 @@ type MapTo struct {
 type OsuRuleSet struct {
 	beatMap         *beatmap.BeatMap
 	cursors         map[*graphics.Cursor]*subSet
+	diffPlayers     []*difficultyPlayer
 	scoreMultiplier float64
+	maxComboPortion float64
+	profile         ScoringProfile
 
 	ended bool
 
+	// silent ruleset only judges, hit objects are left untouched
+	silent bool
+
 	mapStats []*MapTo
 	oppDiffs map[difficulty.Modifier][]oppai.Stars
 
@@ -169,16 +181,19 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 	// HACK: we need to cast to float32 then to float64 to lose some precision but calculate them again as float64s to have matching results with osu!stable
 	ruleset.scoreMultiplier = math.RoundToEven((float64(float32(beatMap.Diff.GetHPDrain())) + float64(float32(beatMap.Diff.GetOD())) + float64(float32(beatMap.Diff.GetCS())) + float64(bmath.ClampF32(float32(len(beatMap.HitObjects))/drainTime*8, 0, 16))) / 38 * 5)
 
+	ruleset.maxComboPortion = calculateMaxComboPortion(beatMap.HitObjects)
+
+	ruleset.profile = NewScoringProfile(settings.Gameplay.ScoringProfile, beatMap)
+
 	ruleset.cursors = make(map[*graphics.Cursor]*subSet)
 
 	var diffPlayers []*difficultyPlayer
 
 	for i, cursor := range cursors {
-		diff := difficulty.NewDifficulty(beatMap.Diff.GetHPDrain(), beatMap.Diff.GetCS(), beatMap.Diff.GetOD(), beatMap.Diff.GetAR())
+		diff := beatMap.Diff.Clone()
 		diff.SetMods(mods[i])
-		diff.SetCustomSpeed(beatMap.Diff.CustomSpeed)
 
-		player := &difficultyPlayer{cursor: cursor, diff: diff}
+		player := &difficultyPlayer{cursor: cursor, diff: diff, oldSpinners: ruleset.profile.OldSpinnerScoring(cursor.ReplayVersion)}
 		diffPlayers = append(diffPlayers, player)
 
 		if ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask] == nil {
@@ -192,39 +207,97 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 
 		log.Println(fmt.Sprintf("Calculating HP rates for \"%s\"...", cursor.Name))
 
-		hp := NewHealthProcessor(beatMap, diff, !cursor.OldSpinnerScoring)
+		hp := NewHealthProcessor(beatMap, diff, !player.oldSpinners)
 		hp.CalculateRate()
 		hp.ResetHp()
 
-		recoveries := 0
-		if diff.CheckModActive(difficulty.Easy) {
-			recoveries = 2
-		}
+		ruleset.cursors[cursor] = newSubSet(player, hp)
+	}
+
+	ruleset.diffPlayers = diffPlayers
+
+	ruleset.createHitObjects(diffPlayers, math.Inf(-1))
+
+	return ruleset
+}
+
+func newSubSet(player *difficultyPlayer, hp *HealthProcessor) *subSet {
+	recoveries := 0
+	if player.diff.CheckModActive(difficulty.Easy) {
+		recoveries = 2
+	}
 
-		ruleset.cursors[cursor] = &subSet{player, 0, 100, 0, 0, 0, mods[i].GetScoreMultiplier(), 0, NONE, &oppai.PPv2{}, make(map[HitResult]int64), 0, 0, hp, 0, 0, recoveries}
+	return &subSet{
+		player:        player,
+		accuracy:      100,
+		modMultiplier: player.diff.Mods.GetScoreMultiplier(),
+		grade:         NONE,
+		ppv2:          &oppai.PPv2{},
+		hits:          make(map[HitResult]int64),
+		hp:            hp,
+		recoveries:    recoveries,
+		scoreV2:       player.diff.Mods.Active(difficulty.ScoreV2),
 	}
+}
+
+// createHitObjects queues objects starting at or after the given time for judgement
+func (set *OsuRuleSet) createHitObjects(players []*difficultyPlayer, from float64) {
+	for _, obj := range set.beatMap.HitObjects {
+		if obj.GetStartTime() < from {
+			continue
+		}
 
-	for _, obj := range beatMap.HitObjects {
 		if circle, ok := obj.(*objects.Circle); ok {
 			rCircle := new(Circle)
-			rCircle.Init(ruleset, circle, diffPlayers)
-			ruleset.queue = append(ruleset.queue, rCircle)
+			rCircle.Init(set, circle, players)
+			set.queue = append(set.queue, rCircle)
 		}
 
 		if slider, ok := obj.(*objects.Slider); ok {
 			rSlider := new(Slider)
-			rSlider.Init(ruleset, slider, diffPlayers)
-			ruleset.queue = append(ruleset.queue, rSlider)
+			rSlider.Init(set, slider, players)
+			set.queue = append(set.queue, rSlider)
 		}
 
 		if spinner, ok := obj.(*objects.Spinner); ok {
 			rSpinner := new(Spinner)
-			rSpinner.Init(ruleset, spinner, diffPlayers)
-			ruleset.queue = append(ruleset.queue, rSpinner)
+			rSpinner.Init(set, spinner, players)
+			set.queue = append(set.queue, rSpinner)
 		}
 	}
+}
 
-	return ruleset
+// Rewind resets scores, health and judgements of all players, objects starting at or after the given time will be judged again
+func (set *OsuRuleSet) Rewind(time int64) {
+	for _, player := range set.diffPlayers {
+		subSet := set.cursors[player.cursor]
+
+		subSet.hp.ResetHp()
+		subSet.hp.lastTime = time
+
+		set.cursors[player.cursor] = newSubSet(player, subSet.hp)
+	}
+
+	set.queue = nil
+	set.processed = nil
+	set.ended = false
+
+	set.createHitObjects(set.diffPlayers, float64(time))
+}
+
+// SetSilent stops the ruleset from arming, shaking and playing sounds of hit objects, used when judgements don't drive what's shown on screen
+func (set *OsuRuleSet) SetSilent(silent bool) {
+	set.silent = silent
+}
+
+// showsFeedback returns true if judgements of an object judged for given amount of players are shown on it
+func (set *OsuRuleSet) showsFeedback(players int) bool {
+	return players == 1 && !settings.HEADLESS && !set.silent
+}
+
+// IsNewInputHandling returns true if object ends are judged only on cursor's replay frames
+func (set *OsuRuleSet) IsNewInputHandling(cursor *graphics.Cursor) bool {
+	return set.profile.NewInputHandling(cursor.ReplayVersion)
 }
 
 func (set *OsuRuleSet) Update(time int64) {
@@ -393,17 +466,7 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, number in
 
 	subSet := set.cursors[cursor]
 
-	combo := bmath.MaxI64(subSet.combo-1, 0)
-
-	if result != SliderMiss {
-		increase := result.ScoreValue()
-
-		if raw {
-			subSet.score += increase
-		} else {
-			subSet.score += increase + int64(float64(increase)*float64(combo)*set.scoreMultiplier*subSet.modMultiplier/25.0)
-		}
-	}
+	set.profile.AddResult(set, subSet, result, raw)
 
 	if result&BaseHitsM > 0 {
 		subSet.rawScore += result.ScoreValue()
@@ -419,35 +482,7 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, number in
 
 	subSet.maxCombo = bmath.MaxI64(subSet.combo, subSet.maxCombo)
 
-	if subSet.numObjects == 0 {
-		subSet.accuracy = 100
-	} else {
-		subSet.accuracy = 100 * float64(subSet.rawScore) / float64(subSet.numObjects*300)
-	}
-
-	ratio := float64(subSet.hits[Hit300]) / float64(subSet.numObjects)
-
-	if subSet.hits[Hit300] == subSet.numObjects {
-		if subSet.player.diff.Mods&(difficulty.Hidden|difficulty.Flashlight) > 0 {
-			subSet.grade = SSH
-		} else {
-			subSet.grade = SS
-		}
-	} else if ratio > 0.9 && float64(subSet.hits[Hit50])/float64(subSet.numObjects) < 0.01 && subSet.hits[Miss] == 0 {
-		if subSet.player.diff.Mods&(difficulty.Hidden|difficulty.Flashlight) > 0 {
-			subSet.grade = SH
-		} else {
-			subSet.grade = S
-		}
-	} else if ratio > 0.8 && subSet.hits[Miss] == 0 || ratio > 0.9 {
-		subSet.grade = A
-	} else if ratio > 0.7 && subSet.hits[Miss] == 0 || ratio > 0.8 {
-		subSet.grade = B
-	} else if ratio > 0.6 {
-		subSet.grade = C
-	} else {
-		subSet.grade = D
-	}
+	set.profile.Update(set, subSet)
 
 	index := bmath.MaxI64(0, subSet.numObjects-1)
 
@@ -511,7 +546,7 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, number in
 		set.hitListener(cursor, time, number, vector.NewVec2f(x, y).Copy64(), result, comboResult, subSet.ppv2.Total, subSet.score)
 	}
 
-	if len(set.cursors) == 1 && !settings.RECORD {
+	if len(set.cursors) == 1 && !settings.RECORD && !settings.HEADLESS && !set.silent {
 		log.Println(fmt.Sprintf(
 			"Got: %3d, Combo: %4d, Max Combo: %4d, Score: %9d, Acc: %6.2f%%, 300: %4d, 100: %3d, 50: %2d, miss: %2d, from: %d, at: %d, pos: %.0fx%.0f, pp: %.2f",
 			result.ScoreValue(),
@@ -604,6 +639,11 @@ func (set *OsuRuleSet) IsPerfect(cursor *graphics.Cursor) bool {
 	return subSet.maxCombo == int64(set.mapStats[subSet.numObjects-1].maxCombo)
 }
 
+func (set *OsuRuleSet) GetMods(cursor *graphics.Cursor) difficulty.Modifier {
+	subSet := set.cursors[cursor]
+	return subSet.player.diff.Mods
+}
+
 func (set *OsuRuleSet) GetPlayer(cursor *graphics.Cursor) *difficultyPlayer {
 	subSet := set.cursors[cursor]
 	return subSet.player
@@ -613,6 +653,11 @@ func (set *OsuRuleSet) GetProcessed() []HitObject {
 	return set.processed
 }
 
+// IsEnded returns true when all objects have been judged
+func (set *OsuRuleSet) IsEnded() bool {
+	return set.ended
+}
+
 func (set *OsuRuleSet) GetBeatMap() *beatmap.BeatMap {
 	return set.beatMap
 }
@@ -0,0 +1,45 @@
+package osu
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"math"
+)
+
+const (
+	scoreV2ComboPortion    = 700000.0
+	scoreV2AccuracyPortion = 300000.0
+)
+
+// comboPortionValue returns how much given judgement adds to ScoreV2's combo portion
+func comboPortionValue(result HitResult, combo int64) float64 {
+	return float64(result.ScoreValue()) * (1 + float64(combo)/10)
+}
+
+// calculateMaxComboPortion simulates a perfect play to get the highest achievable combo portion
+func calculateMaxComboPortion(hitObjects []objects.IHitObject) float64 {
+	portion := 0.0
+	combo := int64(0)
+
+	for _, o := range hitObjects {
+		if s, ok := o.(*objects.Slider); ok {
+			combo += int64(len(s.ScorePoints)) + 1
+			portion += comboPortionValue(Hit300, combo)
+		} else {
+			portion += comboPortionValue(Hit300, combo)
+			combo++
+		}
+	}
+
+	return portion
+}
+
+func (set *OsuRuleSet) calculateScoreV2(subSet *subSet) int64 {
+	comboPart := 0.0
+	if set.maxComboPortion > 0 {
+		comboPart = subSet.comboPortion / set.maxComboPortion * scoreV2ComboPortion
+	}
+
+	accuracyPart := math.Pow(subSet.accuracy/100, 10) * float64(subSet.numObjects) / float64(len(set.beatMap.HitObjects)) * scoreV2AccuracyPortion
+
+	return int64(math.Round((comboPart+accuracyPart)*subSet.modMultiplier)) + subSet.bonusScore
+}
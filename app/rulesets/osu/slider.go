@@ -85,7 +85,7 @@ func (slider *Slider) Init(ruleSet *OsuRuleSet, object objects.IHitObject, playe
 		}
 
 		if len(slider.state[player].points) > 0 {
-			slider.state[player].points[len(slider.state[player].points)-1].time = bmath.MaxI64(int64(slider.hitSlider.GetStartTime())+int64(slider.hitSlider.GetEndTime()-slider.hitSlider.GetStartTime())/2, int64(slider.hitSlider.GetEndTime())-36) //slider ends 36ms before the real end for scoring
+			slider.state[player].points[len(slider.state[player].points)-1].time = bmath.MaxI64(int64(slider.hitSlider.GetStartTime())+int64(slider.hitSlider.GetEndTime()-slider.hitSlider.GetStartTime())/2, int64(slider.hitSlider.GetEndTime())-ruleSet.profile.SliderEndOffset()) //slider ends 36ms before the real end for scoring
 			slider.state[player].points[len(slider.state[player].points)-1].scoreGiven = SliderEnd
 		}
 	}
@@ -124,16 +124,14 @@ func (slider *Slider) UpdateClickFor(player *difficultyPlayer, time int64) bool
 			hit := SliderMiss
 			combo := ComboResults.Reset
 
-			relative := int64(math.Abs(float64(time) - slider.hitSlider.GetStartTime()))
-
-			if relative < player.diff.Hit50 {
+			if slider.ruleSet.profile.JudgeHit(player.diff, math.Abs(float64(time)-slider.hitSlider.GetStartTime())) != Miss {
 				hit = SliderStart
 				state.startScored = true
 				combo = ComboResults.Increase
 			}
 
 			if hit != Ignore {
-				if len(slider.players) == 1 {
+				if slider.ruleSet.showsFeedback(len(slider.players)) {
 					slider.hitSlider.HitEdge(0, float64(time), hit != SliderMiss)
 				}
 
@@ -217,7 +215,7 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64) bool {
 			state.sliding = true
 			state.slideStart = time
 
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showsFeedback(len(slider.players)) {
 				slider.hitSlider.InitSlide(float64(time))
 			}
 		}
@@ -262,7 +260,7 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64) bool {
 		}
 
 		if !allowable && state.sliding && state.scored+state.missed < len(state.points) {
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showsFeedback(len(slider.players)) {
 				slider.hitSlider.KillSlide(float64(time))
 			}
 
@@ -277,7 +275,7 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64) bool {
 	state := slider.state[player]
 
 	if time > int64(slider.hitSlider.GetStartTime())+player.diff.Hit50 && !state.isStartHit {
-		if len(slider.players) == 1 {
+		if slider.ruleSet.showsFeedback(len(slider.players)) {
 			slider.hitSlider.ArmStart(false, float64(time))
 		}
 
@@ -304,7 +302,7 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64) bool {
 
 		rate := float64(state.scored) / float64(len(state.points)+1)
 
-		if rate > 0 && len(slider.players) == 1 {
+		if rate > 0 && slider.ruleSet.showsFeedback(len(slider.players)) {
 			slider.hitSlider.HitEdge(len(slider.hitSlider.TickReverse), float64(time), true)
 		}
 
@@ -53,7 +53,7 @@ func (spinner *Spinner) Init(ruleSet *OsuRuleSet, object objects.IHitObject, pla
 	for _, player := range spinner.players {
 		spinner.state[player] = new(spinnerstate)
 		spinner.fadeStartRelative = math.Min(spinner.fadeStartRelative, player.diff.Preempt)
-		spinner.state[player].requirement = int64(float64(spinnerTime) / 1000 * player.diff.SpinnerRatio)
+		spinner.state[player].requirement = ruleSet.profile.SpinnerRequirement(player.diff, float64(spinnerTime))
 		spinner.state[player].frameVariance = FrameTime
 	}
 
@@ -85,7 +85,7 @@ func (spinner *Spinner) UpdateFor(player *difficultyPlayer, time int64) bool {
 
 			mouseAngle := float64(player.cursor.RawPosition.Sub(spinnerPosition).AngleR())
 
-			if !player.cursor.OldSpinnerScoring && !state.updatedBefore {
+			if !player.oldSpinners && !state.updatedBefore {
 				state.lastAngle = mouseAngle
 				state.updatedBefore = true
 			}
@@ -151,7 +151,7 @@ func (spinner *Spinner) UpdateFor(player *difficultyPlayer, time int64) bool {
 
 			state.currentVelocity = math.Max(-0.05, math.Min(state.currentVelocity, 0.05))
 
-			if len(spinner.players) == 1 {
+			if spinner.ruleSet.showsFeedback(len(spinner.players)) {
 				if state.currentVelocity == 0 {
 					spinner.hitSpinner.StopSpinSample()
 				} else {
@@ -164,7 +164,7 @@ func (spinner *Spinner) UpdateFor(player *difficultyPlayer, time int64) bool {
 			state.rotationCountFD += rotationAddition
 			state.rotationCountF += math.Abs(rotationAddition / math.Pi)
 
-			if len(spinner.players) == 1 {
+			if spinner.ruleSet.showsFeedback(len(spinner.players)) {
 				spinner.hitSpinner.SetRotation(player.diff.GetModifiedTime(state.rotationCountFD))
 				spinner.hitSpinner.SetRPM(state.rpm)
 				spinner.hitSpinner.UpdateCompletion(state.rotationCountF / float64(state.requirement))
@@ -175,12 +175,12 @@ func (spinner *Spinner) UpdateFor(player *difficultyPlayer, time int64) bool {
 			if state.rotationCount != state.lastRotationCount {
 				state.scoringRotationCount++
 
-				if state.scoringRotationCount == spinner.getRequirementClear(player) && len(spinner.players) == 1 {
+				if state.scoringRotationCount == spinner.getRequirementClear(player) && spinner.ruleSet.showsFeedback(len(spinner.players)) {
 					spinner.hitSpinner.Clear()
 				}
 
 				if state.scoringRotationCount > state.requirement+3 && (state.scoringRotationCount-(state.requirement+3))%2 == 0 {
-					if len(spinner.players) == 1 {
+					if spinner.ruleSet.showsFeedback(len(spinner.players)) {
 						spinner.hitSpinner.Bonus()
 					}
 
@@ -206,7 +206,7 @@ func (spinner *Spinner) UpdatePostFor(player *difficultyPlayer, time int64) bool
 		hit := Miss
 		combo := ComboResults.Reset
 
-		if (!player.cursor.OldSpinnerScoring && spinner.state[player].requirement == 0) || state.scoringRotationCount >= spinner.getRequirementGreat(player) {
+		if (!player.oldSpinners && spinner.state[player].requirement == 0) || state.scoringRotationCount >= spinner.getRequirementGreat(player) {
 			hit = Hit300
 		} else if state.scoringRotationCount >= spinner.getRequirementOk(player) {
 			hit = Hit100
@@ -218,7 +218,7 @@ func (spinner *Spinner) UpdatePostFor(player *difficultyPlayer, time int64) bool
 			combo = ComboResults.Increase
 		}
 
-		if len(spinner.players) == 1 {
+		if spinner.ruleSet.showsFeedback(len(spinner.players)) {
 			spinner.hitSpinner.StopSpinSample()
 			spinner.hitSpinner.Hit(float64(time), hit != Miss)
 		}
@@ -255,7 +255,7 @@ func (spinner *Spinner) GetFadeTime() int64 {
 
 // new vs old spinner handling helpers
 func (spinner *Spinner) getRequirementMeh(player *difficultyPlayer) int64 {
-	if player.cursor.OldSpinnerScoring {
+	if player.oldSpinners {
 		return spinner.state[player].requirement
 	}
 
@@ -263,7 +263,7 @@ func (spinner *Spinner) getRequirementMeh(player *difficultyPlayer) int64 {
 }
 
 func (spinner *Spinner) getRequirementOk(player *difficultyPlayer) int64 {
-	if player.cursor.OldSpinnerScoring {
+	if player.oldSpinners {
 		return spinner.state[player].requirement + 1
 	}
 
@@ -271,7 +271,7 @@ func (spinner *Spinner) getRequirementOk(player *difficultyPlayer) int64 {
 }
 
 func (spinner *Spinner) getRequirementGreat(player *difficultyPlayer) int64 {
-	if player.cursor.OldSpinnerScoring {
+	if player.oldSpinners {
 		return spinner.state[player].requirement + 2
 	}
 
@@ -279,7 +279,7 @@ func (spinner *Spinner) getRequirementGreat(player *difficultyPlayer) int64 {
 }
 
 func (spinner *Spinner) getRequirementClear(player *difficultyPlayer) int64 {
-	if player.cursor.OldSpinnerScoring {
+	if player.oldSpinners {
 		return spinner.state[player].requirement + 1
 	}
 
@@ -0,0 +1,151 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"math"
+)
+
+type ObjectType int64
+
+// legacyVelocityMultiplier scales osu!taiko slider velocity in osu!stable, converted slider lengths are scaled by it too
+const legacyVelocityMultiplier = 1.4
+
+const (
+	Don = ObjectType(iota)
+	Kat
+	DrumRoll
+	Swell
+)
+
+// Object is a single taiko hit, drum roll or swell converted from an osu!standard object
+type Object struct {
+	Source objects.IHitObject
+
+	Index int
+	Type  ObjectType
+	Big   bool
+
+	// Raw hitsound flags of the source object
+	HitSound int
+
+	StartTime int64
+	EndTime   int64
+
+	// Beat length at object's start with slider velocity applied, used to calculate scroll speed
+	BeatLength float64
+
+	// Times of drum roll ticks
+	Ticks []int64
+
+	// Amount of alternating hits needed to clear a swell
+	RequiredHits int64
+}
+
+// IsHit returns true for don and kat hits
+func (object *Object) IsHit() bool {
+	return object.Type == Don || object.Type == Kat
+}
+
+func hitType(hitSound int) ObjectType {
+	if hitSound&(2|8) > 0 {
+		return Kat
+	}
+
+	return Don
+}
+
+func convertObjects(beatMap *beatmap.BeatMap) (converted []*Object) {
+	timings := beatMap.Timings
+
+	add := func(source objects.IHitObject, oType ObjectType, hitSound int, startTime, endTime int64) *Object {
+		point := timings.GetPoint(float64(startTime))
+
+		object := &Object{
+			Source:     source,
+			Index:      len(converted),
+			Type:       oType,
+			Big:        hitSound&4 > 0,
+			HitSound:   hitSound,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			BeatLength: point.Bpm,
+		}
+
+		converted = append(converted, object)
+
+		return object
+	}
+
+	for _, o := range beatMap.HitObjects {
+		hitSound := 0
+
+		switch obj := o.(type) {
+		case *objects.Circle:
+			hitSound = obj.HitSound
+		case *objects.Slider:
+			hitSound = obj.HitSound
+		case *objects.Spinner:
+			hitSound = obj.HitSound
+		}
+
+		startTime := int64(o.GetStartTime())
+		endTime := int64(o.GetEndTime())
+
+		switch o.GetType() {
+		case objects.CIRCLE:
+			add(o, hitType(hitSound), hitSound, startTime, startTime)
+		case objects.SLIDER:
+			slider := o.(*objects.Slider)
+
+			point := timings.GetPoint(o.GetStartTime())
+
+			spans := math.Max(1, float64(slider.GetRepeats()))
+
+			// Like in osu!stable, only the length is scaled while velocity stays the same, so drum rolls last longer than source sliders
+			distance := slider.GetPixelLength() * spans * legacyVelocityMultiplier
+			taikoDuration := float64(int64(distance / (100 * timings.SliderMult) * point.Bpm))
+
+			// osu!stable uses beat length with slider velocity applied only in beatmaps older than v8
+			beatLength := point.BaseBpm
+			if beatMap.Version < 8 {
+				beatLength = point.Bpm
+			}
+
+			tickSpacing := math.Min(beatLength/timings.TickRate, taikoDuration/spans)
+
+			// Short sliders become streams of hits, long ones become drum rolls
+			if tickSpacing > 0 && taikoDuration < 2*beatLength {
+				for t := o.GetStartTime(); t <= o.GetStartTime()+taikoDuration+tickSpacing/8; t += tickSpacing {
+					add(o, hitType(hitSound), hitSound, int64(t), int64(t))
+				}
+
+				continue
+			}
+
+			rollEnd := o.GetStartTime() + taikoDuration
+
+			roll := add(o, DrumRoll, hitSound, startTime, int64(rollEnd))
+
+			tickRate := 4.0
+			if timings.TickRate == 3 {
+				tickRate = 3
+			}
+
+			rollSpacing := point.BaseBpm / tickRate
+
+			for t := o.GetStartTime(); t < rollEnd+rollSpacing/2; t += rollSpacing {
+				roll.Ticks = append(roll.Ticks, int64(t))
+			}
+		case objects.SPINNER:
+			swell := add(o, Swell, 0, startTime, endTime)
+
+			ratio := difficulty.DifficultyRate(beatMap.Diff.GetOD(), 3, 5, 7.5)
+
+			swell.RequiredHits = int64(math.Max(1, (o.GetEndTime()-o.GetStartTime())/1000*ratio*1.65))
+		}
+	}
+
+	return
+}
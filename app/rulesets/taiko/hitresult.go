@@ -0,0 +1,38 @@
+package taiko
+
+type HitResult int64
+
+const (
+	Miss = HitResult(iota)
+	Good
+	Great
+)
+
+var HitResultsText = []string{"Miss", "Good", "Great"}
+
+// ScoreValue is the value used in accuracy and base score calculation, goods are worth half of greats
+func (result HitResult) ScoreValue() int64 {
+	switch result {
+	case Great:
+		return 300
+	case Good:
+		return 150
+	}
+
+	return 0
+}
+
+func (result HitResult) healthIncrease() float64 {
+	switch result {
+	case Great:
+		return 3
+	case Good:
+		return 1.1
+	}
+
+	return 0
+}
+
+func (result HitResult) String() string {
+	return HitResultsText[result]
+}
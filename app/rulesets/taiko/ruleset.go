@@ -0,0 +1,453 @@
+package taiko
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"log"
+	"math"
+)
+
+const MaxScore = 1000000.0
+
+// Time in which a second press of the same type counts as hitting a big note with both hands
+const bigHitWindow = 30
+
+const (
+	DonLeft = uint32(1 << iota)
+	KatLeft
+	DonRight
+	KatRight
+)
+
+const katKeys = KatLeft | KatRight
+
+type ObjectState int64
+
+const (
+	Pending = ObjectState(iota)
+	Hit
+	Missed
+)
+
+type player struct {
+	cursor *graphics.Cursor
+	mods   difficulty.Modifier
+
+	// great, good and miss windows
+	windows [3]float64
+
+	states []ObjectState
+	hits   []int64
+	next   int
+
+	keys uint32
+
+	lastHit       *Object
+	lastHitTime   int64
+	lastHitKat    bool
+	lastHitResult HitResult
+
+	lastSwellKat bool
+
+	baseScore  float64
+	bonusScore float64
+	score      int64
+
+	combo    int64
+	maxCombo int64
+
+	results  [3]int64
+	judged   int64
+	accuracy float64
+	hp       float64
+	grade    osu.Grade
+}
+
+type TaikoRuleSet struct {
+	beatMap *beatmap.BeatMap
+
+	objects []*Object
+
+	hitCount int64
+
+	players map[*graphics.Cursor]*player
+	order   []*player
+
+	ended bool
+
+	hitListener func(cursor *graphics.Cursor, time int64, object *Object, result HitResult, bonus bool)
+}
+
+func NewTaikoRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []difficulty.Modifier) *TaikoRuleSet {
+	log.Println("Creating osu!taiko ruleset...")
+
+	ruleset := new(TaikoRuleSet)
+	ruleset.beatMap = beatMap
+	ruleset.objects = convertObjects(beatMap)
+
+	rolls, swells := 0, 0
+
+	for _, o := range ruleset.objects {
+		switch o.Type {
+		case DrumRoll:
+			rolls++
+		case Swell:
+			swells++
+		default:
+			ruleset.hitCount++
+		}
+	}
+
+	log.Println(fmt.Sprintf("Hits: %d, drum rolls: %d, swells: %d", ruleset.hitCount, rolls, swells))
+
+	ruleset.players = make(map[*graphics.Cursor]*player)
+
+	for i, cursor := range cursors {
+		p := &player{
+			cursor:   cursor,
+			mods:     mods[i],
+			states:   make([]ObjectState, len(ruleset.objects)),
+			hits:     make([]int64, len(ruleset.objects)),
+			accuracy: 100,
+			grade:    osu.NONE,
+		}
+
+		p.windows = calculateWindows(beatMap.Diff.GetOD(), mods[i])
+
+		ruleset.players[cursor] = p
+		ruleset.order = append(ruleset.order, p)
+	}
+
+	return ruleset
+}
+
+func calculateWindows(od float64, mods difficulty.Modifier) [3]float64 {
+	if mods.Active(difficulty.HardRock) {
+		od = math.Min(od*1.4, 10)
+	} else if mods.Active(difficulty.Easy) {
+		od /= 2
+	}
+
+	return [3]float64{
+		math.Floor(difficulty.DifficultyRate(od, 50, 35, 20)),
+		math.Floor(difficulty.DifficultyRate(od, 120, 80, 50)),
+		math.Floor(difficulty.DifficultyRate(od, 135, 95, 70)),
+	}
+}
+
+// UpdateKeys processes a change of pressed drum parts at the given time, see DonLeft, KatLeft, DonRight and KatRight
+func (set *TaikoRuleSet) UpdateKeys(cursor *graphics.Cursor, time int64, keys uint32) {
+	p := set.players[cursor]
+	if p == nil {
+		return
+	}
+
+	set.updatePlayer(p, time)
+
+	pressed := keys &^ p.keys
+
+	for _, key := range []uint32{DonLeft, KatLeft, DonRight, KatRight} {
+		if pressed&key > 0 {
+			set.hit(p, time, key&katKeys > 0)
+		}
+	}
+
+	p.keys = keys
+}
+
+func (set *TaikoRuleSet) hit(p *player, time int64, kat bool) {
+	// Second hand on a big note
+	if last := p.lastHit; last != nil && last.Big && p.lastHitKat == kat && time-p.lastHitTime <= bigHitWindow {
+		p.lastHit = nil
+
+		set.addBonus(p, last, time, float64(p.lastHitResult.ScoreValue()))
+
+		return
+	}
+
+	if p.next >= len(set.objects) {
+		return
+	}
+
+	object := set.objects[p.next]
+
+	switch object.Type {
+	case Don, Kat:
+		if float64(object.StartTime-time) > p.windows[2] {
+			return
+		}
+
+		result := p.judge(float64(time - object.StartTime))
+
+		if (object.Type == Kat) != kat {
+			result = Miss
+		}
+
+		set.judge(p, object, time, result)
+
+		if result != Miss {
+			p.lastHit = object
+			p.lastHitTime = time
+			p.lastHitKat = kat
+			p.lastHitResult = result
+		}
+	case DrumRoll:
+		if time < object.StartTime || p.hits[object.Index] >= int64(len(object.Ticks)) {
+			return
+		}
+
+		p.hits[object.Index]++
+
+		value := 300.0
+		if object.Big {
+			value = 600
+		}
+
+		set.addBonus(p, object, time, value)
+	case Swell:
+		if time < object.StartTime {
+			return
+		}
+
+		// Swells require alternating between don and kat
+		if p.hits[object.Index] > 0 && p.lastSwellKat == kat {
+			return
+		}
+
+		p.lastSwellKat = kat
+		p.hits[object.Index]++
+
+		set.addBonus(p, object, time, 0)
+
+		if p.hits[object.Index] >= object.RequiredHits {
+			set.finishSwell(p, object, time)
+		}
+	}
+}
+
+func (p *player) judge(offset float64) HitResult {
+	offset = math.Abs(offset)
+
+	switch {
+	case offset <= p.windows[0]:
+		return Great
+	case offset <= p.windows[1]:
+		return Good
+	}
+
+	return Miss
+}
+
+func (set *TaikoRuleSet) updatePlayer(p *player, time int64) {
+	for p.next < len(set.objects) {
+		object := set.objects[p.next]
+
+		if object.IsHit() {
+			if float64(time-object.StartTime) <= p.windows[2] {
+				break
+			}
+
+			set.judge(p, object, object.StartTime+int64(p.windows[2]), Miss)
+
+			continue
+		}
+
+		if time <= object.EndTime {
+			break
+		}
+
+		if object.Type == Swell {
+			set.finishSwell(p, object, object.EndTime)
+		} else {
+			p.states[object.Index] = Hit
+			p.next++
+		}
+	}
+}
+
+func (set *TaikoRuleSet) finishSwell(p *player, object *Object, time int64) {
+	result := Miss
+
+	switch hits := p.hits[object.Index]; {
+	case hits >= object.RequiredHits:
+		result = Great
+	case hits*2 > object.RequiredHits:
+		result = Good
+	}
+
+	if result != Miss {
+		p.states[object.Index] = Hit
+	} else {
+		p.states[object.Index] = Missed
+	}
+
+	p.next++
+
+	p.bonusScore += float64(result.ScoreValue()) * p.mods.GetScoreMultiplier()
+	set.updateScore(p)
+
+	if set.hitListener != nil {
+		set.hitListener(p.cursor, time, object, result, false)
+	}
+}
+
+// judge applies a judgement of a don or kat hit
+func (set *TaikoRuleSet) judge(p *player, object *Object, time int64, result HitResult) {
+	if result != Miss {
+		p.states[object.Index] = Hit
+	} else {
+		p.states[object.Index] = Missed
+	}
+
+	p.next++
+
+	p.results[result]++
+	p.judged++
+
+	if result == Miss {
+		p.combo = 0
+	} else {
+		p.combo++
+		p.maxCombo = bmath.MaxI64(p.maxCombo, p.combo)
+	}
+
+	p.baseScore += float64(result.ScoreValue())
+
+	p.accuracy = 100 * (p.baseScore / float64(p.judged*300))
+
+	if result == Miss {
+		p.hp -= difficulty.DifficultyRate(set.beatMap.Diff.GetHPDrain(), 0.0018, 0.0075, 0.0120)
+	} else {
+		p.hp += result.healthIncrease() / (float64(set.hitCount) * difficulty.DifficultyRate(set.beatMap.Diff.GetHPDrain(), 0.5, 0.75, 0.98))
+	}
+
+	p.hp = bmath.ClampF64(p.hp, 0, 1)
+
+	p.grade = set.calculateGrade(p)
+
+	set.updateScore(p)
+
+	if set.hitListener != nil {
+		set.hitListener(p.cursor, time, object, result, false)
+	}
+}
+
+func (set *TaikoRuleSet) addBonus(p *player, object *Object, time int64, value float64) {
+	p.bonusScore += value * p.mods.GetScoreMultiplier()
+
+	set.updateScore(p)
+
+	if set.hitListener != nil {
+		set.hitListener(p.cursor, time, object, Great, true)
+	}
+}
+
+// updateScore calculates score the way osu!lazer does: 75% from accuracy, 25% from combo and raw bonus on top
+func (set *TaikoRuleSet) updateScore(p *player) {
+	total := float64(bmath.MaxI64(set.hitCount, 1))
+
+	accuracyPortion := p.baseScore / (total * 300)
+	comboPortion := float64(p.maxCombo) / total
+
+	p.score = int64(math.Round(MaxScore*(0.75*accuracyPortion+0.25*comboPortion)*p.mods.GetScoreMultiplier() + p.bonusScore))
+}
+
+func (set *TaikoRuleSet) calculateGrade(p *player) osu.Grade {
+	silver := p.mods&(difficulty.Hidden|difficulty.Flashlight) > 0
+
+	ratio := float64(p.results[Great]) / float64(bmath.MaxI64(p.judged, 1))
+	perfect := p.results[Miss] == 0
+
+	switch {
+	case ratio == 1 && silver:
+		return osu.SSH
+	case ratio == 1:
+		return osu.SS
+	case ratio > 0.9 && perfect && silver:
+		return osu.SH
+	case ratio > 0.9 && perfect:
+		return osu.S
+	case ratio > 0.8 && perfect || ratio > 0.9:
+		return osu.A
+	case ratio > 0.7 && perfect || ratio > 0.8:
+		return osu.B
+	case ratio > 0.6:
+		return osu.C
+	}
+
+	return osu.D
+}
+
+// Update judges hits that were not hit in time and finishes drum rolls and swells
+func (set *TaikoRuleSet) Update(time int64) {
+	ended := true
+
+	for _, p := range set.order {
+		set.updatePlayer(p, time)
+
+		if p.next < len(set.objects) {
+			ended = false
+		}
+	}
+
+	set.ended = ended
+}
+
+// SetListener sets a function called after every judgement. Bonus is true for drum roll, swell and big note hits that don't judge the object.
+func (set *TaikoRuleSet) SetListener(listener func(cursor *graphics.Cursor, time int64, object *Object, result HitResult, bonus bool)) {
+	set.hitListener = listener
+}
+
+func (set *TaikoRuleSet) GetObjects() []*Object {
+	return set.objects
+}
+
+func (set *TaikoRuleSet) GetObjectState(cursor *graphics.Cursor, object *Object) ObjectState {
+	return set.players[cursor].states[object.Index]
+}
+
+// GetObjectHits returns the amount of times a drum roll or swell was hit
+func (set *TaikoRuleSet) GetObjectHits(cursor *graphics.Cursor, object *Object) int64 {
+	return set.players[cursor].hits[object.Index]
+}
+
+// GetKeys returns currently pressed drum parts
+func (set *TaikoRuleSet) GetKeys(cursor *graphics.Cursor) uint32 {
+	return set.players[cursor].keys
+}
+
+func (set *TaikoRuleSet) GetResults(cursor *graphics.Cursor) (float64, int64, int64, osu.Grade) {
+	p := set.players[cursor]
+	return p.accuracy, p.maxCombo, p.score, p.grade
+}
+
+// GetHits returns amounts of greats, goods and misses
+func (set *TaikoRuleSet) GetHits(cursor *graphics.Cursor) (int64, int64, int64) {
+	p := set.players[cursor]
+	return p.results[Great], p.results[Good], p.results[Miss]
+}
+
+func (set *TaikoRuleSet) GetCombo(cursor *graphics.Cursor) int64 {
+	return set.players[cursor].combo
+}
+
+func (set *TaikoRuleSet) GetHP(cursor *graphics.Cursor) float64 {
+	return set.players[cursor].hp
+}
+
+func (set *TaikoRuleSet) GetMods(cursor *graphics.Cursor) difficulty.Modifier {
+	return set.players[cursor].mods
+}
+
+// IsEnded returns true when all objects have been judged
+func (set *TaikoRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+func (set *TaikoRuleSet) GetBeatMap() *beatmap.BeatMap {
+	return set.beatMap
+}
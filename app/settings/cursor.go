@@ -45,6 +45,7 @@ func initCursor() *cursor {
 		AdditiveBlending:            true,
 		CursorRipples:               true,
 		SmokeEnabled:                true,
+		ReplayInterpolation:         "linear",
 	}
 }
 
@@ -74,6 +75,7 @@ type cursor struct {
 	AdditiveBlending            bool
 	CursorRipples               bool
 	SmokeEnabled                bool
+	ReplayInterpolation         string //"linear" - how cursor is drawn between replay frames: "linear", "catmull" or "none", judgements always use raw frames
 }
 
 func (cr *cursor) GetColors(divides, cursors int, beatScale, alpha float64) []color2.Color {
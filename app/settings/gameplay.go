@@ -69,6 +69,22 @@ func initGameplay() *gameplay {
 			HideInReplays: false,
 			FoldInReplays: false,
 		},
+		StrainGraph: &hudElement{
+			Show:    false,
+			Scale:   1.0,
+			Opacity: 1.0,
+		},
+		Mania: &mania{
+			ScrollTime:  800,
+			ColumnWidth: 70,
+			HitPosition: 900,
+			ShowKeys:    true,
+		},
+		Taiko: &taiko{
+			ScrollSpeed: 1.0,
+			PositionY:   360,
+			Scale:       1.0,
+		},
 		Boundaries: &boundaries{
 			Enabled:         true,
 			BorderThickness: 1,
@@ -91,6 +107,7 @@ func initGameplay() *gameplay {
 		ShowWarningArrows: true,
 		FlashlightDim:     1,
 		PlayUsername:      "Guest",
+		ScoringProfile:    "stable",
 	}
 }
 
@@ -103,12 +120,16 @@ type gameplay struct {
 	KeyOverlay        *hudElement
 	ScoreBoard        *scoreBoard
 	Mods              *mods
+	StrainGraph       *hudElement
+	Mania             *mania
+	Taiko             *taiko
 	Boundaries        *boundaries
 	ShowResultsScreen bool
 	ResultsScreenTime float64
 	ShowWarningArrows bool
 	FlashlightDim     float64
 	PlayUsername      string
+	ScoringProfile    string // "stable" or "lazer", how judgements, score, accuracy and grades are calculated
 }
 
 type boundaries struct {
@@ -124,6 +145,31 @@ type boundaries struct {
 	BackgroundOpacity float64
 }
 
+type mania struct {
+	// Time in ms it takes for a note to scroll from the top of the screen to the judgement line
+	ScrollTime float64
+
+	// Width of a single column, in 1080p pixels
+	ColumnWidth float64
+
+	// Y position of the judgement line, in 1080p pixels
+	HitPosition float64
+
+	// Whether to draw mania-key textures below the judgement line
+	ShowKeys bool
+}
+
+type taiko struct {
+	// Multiplier of the scroll speed defined by the beatmap
+	ScrollSpeed float64
+
+	// Y position of the centre of the playfield, in 1080p pixels
+	PositionY float64
+
+	// Scale of the playfield, 1.0 means 200 pixel high lane
+	Scale float64
+}
+
 type hudElement struct {
 	Show    bool
 	Scale   float64
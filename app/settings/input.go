@@ -8,6 +8,12 @@ func initInput() *input {
 		RightKey:             "X",
 		RestartKey:           "`",
 		SmokeKey:             "C",
+		PracticeRewindKey:    "R",
+		PracticeStartKey:     "[",
+		PracticeEndKey:       "]",
+		PracticeClearKey:     "\\",
+		PracticeSlowerKey:    "-",
+		PracticeFasterKey:    "=",
 		MouseButtonsDisabled: true,
 		MouseHighPrecision:   false,
 		MouseSensitivity:     1,
@@ -19,6 +25,12 @@ type input struct {
 	RightKey             string
 	RestartKey           string
 	SmokeKey             string
+	PracticeRewindKey    string
+	PracticeStartKey     string
+	PracticeEndKey       string
+	PracticeClearKey     string
+	PracticeSlowerKey    string
+	PracticeFasterKey    string
 	MouseButtonsDisabled bool
 	MouseHighPrecision   bool
 	MouseSensitivity     float64
@@ -9,7 +9,10 @@ func initKnockout() *knockout {
 		//ApiKey:             "",
 		Mode: ComboBreak,
 		//LocalReplays:       false,
-		//OnlineReplays:      true,
+		OnlineReplays:      false,
+		ApiUrl:             "https://osu.ppy.sh",
+		ApiToken:           "",
+		OnlineLimit:        50,
 		ExcludeMods:        "EZHT",
 		MaxPlayers:         50,
 		BubbleMinimumCombo: 200,
@@ -34,8 +37,17 @@ type knockout struct {
 	// Whether to load local replays. They have to be put in ./replays/beatmapMD5/
 	//LocalReplays bool
 
-	// Whether to load online replays using osu!api
-	//OnlineReplays bool
+	// Whether to download top replays from osu!api v2 compatible server to ./replays/beatmapMD5/ before knockout starts
+	OnlineReplays bool
+
+	// Base URL of the API server, can point to a local mirror
+	ApiUrl string
+
+	// OAuth access token sent with every request, can be left empty if server doesn't require it
+	ApiToken string
+
+	// How many leaderboard replays should be downloaded
+	OnlineLimit int
 
 	// Exclude plays which contain one of the mods set here
 	ExcludeMods string
@@ -4,20 +4,26 @@ var Recording = initRecording()
 
 func initRecording() *recording {
 	return &recording{
-		FrameWidth:     1920,
-		FrameHeight:    1080,
-		FPS:            60,
-		Encoder:        "libx264",
-		EncoderOptions: "-crf 14",
-		Profile:        "high",
-		Preset:         "faster",
-		PixelFormat:    "yuv420p",
-		Filters:        "",
-		AudioCodec:     "aac",
-		AudioBitrate:   "320k",
-		AudioFilters:   "",
-		OutputDir:      "videos",
-		Container:      "mp4",
+		FrameWidth:        1920,
+		FrameHeight:       1080,
+		FPS:               60,
+		Encoder:           "libx264",
+		EncoderOptions:    "-crf 14",
+		Profile:           "high",
+		Preset:            "faster",
+		PixelFormat:       "yuv420p",
+		Filters:           "",
+		AudioCodec:        "aac",
+		AudioBitrate:      "320k",
+		AudioFilters:      "",
+		OutputDir:         "videos",
+		Container:         "mp4",
+		OutputType:        "video",
+		ImageFormat:       "png",
+		IntermediateCodec: "ffv1",
+		Alpha:             false,
+		SinglePass:        true,
+		StatusAddress:     "",
 		MotionBlur: &motionblur{
 			Enabled:              false,
 			OversampleMultiplier: 3,
@@ -47,7 +53,25 @@ type recording struct {
 	AudioFilters   string
 	OutputDir      string
 	Container      string
-	MotionBlur     *motionblur
+
+	// OutputType is one of: video, images (numbered ImageFormat sequence), intermediate (lossless IntermediateCodec file).
+	// Images and intermediate files bypass Encoder, audio is saved next to them as WAV with a timing file.
+	OutputType        string
+	ImageFormat       string
+	IntermediateCodec string
+
+	// Alpha renders the playfield over transparent background without dim, blur, storyboard and triangles, frames are read as RGBA.
+	// Encoder and PixelFormat have to support alpha in video mode to keep it (e.g. prores_ks with yuva444p10le, libvpx-vp9 with yuva420p).
+	Alpha bool
+
+	SinglePass    bool
+	StatusAddress string
+	MotionBlur    *motionblur
+}
+
+// IsTransparent returns true if recorded frames keep alpha instead of being drawn over the background
+func (r *recording) IsTransparent() bool {
+	return RECORD && r.Alpha
 }
 
 type motionblur struct {
@@ -25,8 +25,12 @@ var END = math.Inf(1)
 var KNOCKOUT = false
 var PLAYERS = 1
 var DIVIDES = 2
-var SPEED = 1.0
 var PITCH = 1.0
 var TAG = 1
 var RECORD = false
 var REPLAY = ""
+var HEADLESS = false
+var SAVEREPLAY = false
+var PRACTICE = false
+var PRACTICERATE = 1.0
+var REWIND = 5.0
@@ -96,6 +96,32 @@ func checkInit() {
 	log.Println(fmt.Sprintf("SkinManager: Skin \"%s\" loaded.", CurrentSkin))
 }
 
+// Unload drops elements of the current skin, settings.Skin.CurrentSkin is loaded on next use.
+// Default skin elements stay in the atlas.
+func Unload() {
+	fontLock.Lock()
+	soundLock.Lock()
+	textureLock.Lock()
+
+	defer fontLock.Unlock()
+	defer soundLock.Unlock()
+	defer textureLock.Unlock()
+
+	info = nil
+	pathCache = nil
+
+	for _, rg := range skinCache {
+		if rg != nil {
+			delete(sourceCache, rg)
+		}
+	}
+
+	animationCache = make(map[string][]*texture.TextureRegion)
+	skinCache = make(map[string]*texture.TextureRegion)
+	fontCache = make(map[string]*font.Font)
+	sampleCache = make(map[string]*bass.Sample)
+}
+
 func GetInfo() *SkinInfo {
 	checkInit()
 	return info
@@ -416,17 +442,18 @@ func AddBeatmapColor(data []string) {
 }
 
 func FinishBeatmapColors() {
-	if len(beatmapColorsI) > 0 {
-		sort.SliceStable(beatmapColorsI, func(i, j int) bool {
-			return beatmapColorsI[i].index <= beatmapColorsI[j].index
-		})
+	sort.SliceStable(beatmapColorsI, func(i, j int) bool {
+		return beatmapColorsI[i].index <= beatmapColorsI[j].index
+	})
 
-		beatmapColors = make([]color.Color, 0)
+	beatmapColors = make([]color.Color, 0)
 
-		for _, c := range beatmapColorsI {
-			beatmapColors = append(beatmapColors, c.color)
-		}
+	for _, c := range beatmapColorsI {
+		beatmapColors = append(beatmapColors, c.color)
 	}
+
+	// Next beatmap starts with its own colors
+	beatmapColorsI = beatmapColorsI[:0]
 }
 
 func GetColors() []color.Color {
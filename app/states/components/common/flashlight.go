@@ -63,8 +63,8 @@ func NewFlashlight(beatMap *beatmap.BeatMap) *Flashlight {
 
 	size := animation.NewGlider(DefaultFlashlightSize * 8)
 
-	startTime := beatMap.HitObjects[0].GetStartTime() / settings.SPEED
-	endTime := (beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime() + float64(beatMap.Diff.Hit50+5)) / settings.SPEED
+	startTime := beatMap.Diff.ToRealTime(beatMap.HitObjects[0].GetStartTime())
+	endTime := beatMap.Diff.ToRealTime(beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime() + float64(beatMap.Diff.Hit50+5))
 
 	size.AddEvent(startTime-DefaultFlashlightDuration, startTime, DefaultFlashlightSize)
 	size.AddEvent(endTime, endTime+DefaultFlashlightDuration, DefaultFlashlightSize*8)
@@ -120,8 +120,8 @@ func (fl *Flashlight) Update(time float64) {
 	for i := fl.breakIndex + 1; i < len(fl.beatMap.Pauses); i++ {
 		pause := fl.beatMap.Pauses[i]
 
-		pauseStart := pause.GetStartTime() / settings.SPEED
-		pauseEnd := pause.GetEndTime() / settings.SPEED
+		pauseStart := fl.beatMap.Diff.ToRealTime(pause.GetStartTime())
+		pauseEnd := fl.beatMap.Diff.ToRealTime(pause.GetEndTime())
 
 		if time < pauseStart {
 			break
@@ -0,0 +1,199 @@
+package common
+
+import (
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const (
+	timelineSeekStep  = 5000.0
+	timelineFrameStep = 1000.0 / 60
+
+	timelineHeight      = 8.0
+	timelineHoverHeight = 60.0
+
+	// How long the timeline stays visible after it was used
+	timelineShowTime = 2000.0
+)
+
+// Timeline lets the user pause and scrub through a replay with arrow keys, frame stepping and a clickable progress bar
+type Timeline struct {
+	font *font.Font
+
+	start, end float64
+
+	paused bool
+
+	seekBack, seekForward, togglePause, stepBack, stepForward bool
+
+	wasClicked bool
+
+	seekPending bool
+	seekTime    float64
+
+	time      float64
+	alpha     float64
+	showUntil float64
+	hovered   bool
+}
+
+func NewTimeline(start, end float64) *Timeline {
+	return &Timeline{
+		font:  font.GetFont("Exo 2 Bold"),
+		start: start,
+		end:   end,
+	}
+}
+
+func (timeline *Timeline) KeyEvent(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, _ glfw.ModifierKey) {
+	// Key repeat is ignored because every backward seek simulates the beatmap again
+	if action != glfw.Press {
+		return
+	}
+
+	switch key {
+	case glfw.KeyLeft:
+		timeline.seekBack = true
+	case glfw.KeyRight:
+		timeline.seekForward = true
+	case glfw.KeyComma:
+		timeline.stepBack = true
+	case glfw.KeyPeriod:
+		timeline.stepForward = true
+	case glfw.KeySpace:
+		timeline.togglePause = true
+	}
+}
+
+// Update processes input, it has to be called every update with the current time of the beatmap
+func (timeline *Timeline) Update(time, delta, scaledWidth, scaledHeight float64) {
+	timeline.time = time
+
+	if timeline.togglePause {
+		timeline.togglePause = false
+		timeline.paused = !timeline.paused
+		timeline.show(time)
+	}
+
+	switch {
+	case timeline.seekBack:
+		timeline.requestSeek(time - timelineSeekStep)
+	case timeline.seekForward:
+		timeline.requestSeek(time + timelineSeekStep)
+	case timeline.stepBack:
+		timeline.paused = true
+		timeline.requestSeek(time - timelineFrameStep)
+	case timeline.stepForward:
+		timeline.paused = true
+		timeline.requestSeek(time + timelineFrameStep)
+	}
+
+	timeline.seekBack, timeline.seekForward, timeline.stepBack, timeline.stepForward = false, false, false, false
+
+	timeline.updateMouse(scaledWidth, scaledHeight)
+
+	target := 0.0
+	if timeline.paused || timeline.hovered || timeline.showUntil > timeline.time {
+		target = 1.0
+	}
+
+	if timeline.alpha < target {
+		timeline.alpha = math.Min(target, timeline.alpha+delta/200)
+	} else {
+		timeline.alpha = math.Max(target, timeline.alpha-delta/200)
+	}
+}
+
+func (timeline *Timeline) updateMouse(scaledWidth, scaledHeight float64) {
+	if input.Win == nil || !input.Focused {
+		timeline.hovered = false
+		return
+	}
+
+	x, y := input.Win.GetCursorPos()
+
+	position := vector.NewVec2d(x*scaledWidth/settings.Graphics.GetWidthF(), y*scaledHeight/settings.Graphics.GetHeightF())
+
+	timeline.hovered = position.Y >= scaledHeight-timelineHoverHeight && position.Y <= scaledHeight
+
+	clicked := input.Win.GetMouseButton(glfw.MouseButtonLeft) == glfw.Press
+
+	if clicked && !timeline.wasClicked && timeline.hovered {
+		timeline.requestSeek(timeline.start + (timeline.end-timeline.start)*position.X/scaledWidth)
+	}
+
+	timeline.wasClicked = clicked
+}
+
+func (timeline *Timeline) requestSeek(time float64) {
+	timeline.seekTime = bmath.ClampF64(time, timeline.start, timeline.end)
+	timeline.seekPending = true
+
+	timeline.show(timeline.seekTime)
+}
+
+func (timeline *Timeline) show(time float64) {
+	timeline.showUntil = time + timelineShowTime
+}
+
+// PollSeek returns the time playback has to be moved to
+func (timeline *Timeline) PollSeek() (float64, bool) {
+	if !timeline.seekPending {
+		return 0, false
+	}
+
+	timeline.seekPending = false
+
+	return timeline.seekTime, true
+}
+
+func (timeline *Timeline) IsPaused() bool {
+	return timeline.paused
+}
+
+func (timeline *Timeline) Draw(batch *batch.QuadBatch, scaledWidth, scaledHeight float64) {
+	if timeline.alpha < 0.01 {
+		return
+	}
+
+	progress := bmath.ClampF64((timeline.time-timeline.start)/(timeline.end-timeline.start), 0, 1)
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+
+	barPos := vector.NewVec2d(0, scaledHeight)
+
+	batch.DrawStObject(barPos, bmath.Origin.BottomLeft, vector.NewVec2d(scaledWidth, timelineHeight), false, false, 0, color2.NewLA(0, 0.6*float32(timeline.alpha)), false, graphics.Pixel.GetRegion())
+	batch.DrawStObject(barPos, bmath.Origin.BottomLeft, vector.NewVec2d(scaledWidth*progress, timelineHeight), false, false, 0, color2.NewLA(1, 0.8*float32(timeline.alpha)), false, graphics.Pixel.GetRegion())
+
+	text := fmt.Sprintf("%s / %s", formatTimelineTime(timeline.time), formatTimelineTime(timeline.end))
+	if timeline.paused {
+		text += " (paused)"
+	}
+
+	size := 20.0
+	textY := scaledHeight - timelineHeight - 4
+
+	batch.SetColor(0, 0, 0, timeline.alpha)
+	timeline.font.DrawOrigin(batch, 8+size*0.1, textY+size*0.1, bmath.Origin.BottomLeft, size, true, text)
+
+	batch.SetColor(1, 1, 1, timeline.alpha)
+	timeline.font.DrawOrigin(batch, 8, textY, bmath.Origin.BottomLeft, size, true, text)
+
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func formatTimelineTime(time float64) string {
+	seconds := int64(math.Max(0, time)) / 1000
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
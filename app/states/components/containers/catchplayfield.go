@@ -0,0 +1,278 @@
+package containers
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"log"
+	"math"
+)
+
+// Y position of catcher's plate in osu!pixels
+const catcherY = 340.0
+
+var fruitTextures = []string{"fruit-pear", "fruit-grapes", "fruit-apple", "fruit-orange"}
+
+var (
+	hyperDashColor = color2.NewIRGB(255, 0, 0)
+	bananaColor    = color2.NewIRGB(255, 240, 0)
+)
+
+type fruitTexture struct {
+	base, overlay *texture.TextureRegion
+}
+
+// CatchPlayfield draws falling osu!catch objects and the catcher in 1080p UI coordinates
+type CatchPlayfield struct {
+	ruleset *catch.CatchRuleSet
+	cursor  *graphics.Cursor
+	objects []*catch.Object
+
+	fruits  []fruitTexture
+	drop    fruitTexture
+	bananas fruitTexture
+
+	catcherIdle, catcherKiai, catcherFail *texture.TextureRegion
+
+	left, top, scale float64
+
+	preempt      float64
+	fruitSize    float64
+	catcherScale float64
+
+	lastMiss float64
+
+	firstObject  int
+	judgedObject int
+}
+
+func NewCatchPlayfield(ruleset *catch.CatchRuleSet, cursor *graphics.Cursor, scaledWidth, scaledHeight float64) *CatchPlayfield {
+	log.Println("Creating osu!catch playfield...")
+
+	beatMap := ruleset.GetBeatMap()
+	mods := ruleset.GetMods(cursor)
+
+	field := &CatchPlayfield{
+		ruleset:  ruleset,
+		cursor:   cursor,
+		objects:  ruleset.GetObjects(),
+		scale:    scaledHeight * 0.8 / 384,
+		lastMiss: math.Inf(-1),
+	}
+
+	field.left = (scaledWidth - catch.PlayfieldWidth*field.scale) / 2
+	field.top = scaledHeight * 0.1
+
+	ar := beatMap.Diff.GetAR()
+	if mods.Active(difficulty.HardRock) {
+		ar = math.Min(ar*1.4, 10)
+	} else if mods.Active(difficulty.Easy) {
+		ar /= 2
+	}
+
+	cs := catch.GetModifiedCS(beatMap.Diff.GetCS(), mods)
+
+	field.preempt = math.Floor(difficulty.DifficultyRate(ar, 1800, 1200, 450))
+	field.fruitSize = 2 * difficulty.DifficultyRate(cs, 54.4, 32, 9.6)
+	field.catcherScale = catch.GetCatcherScale(cs)
+
+	for _, name := range fruitTextures {
+		field.fruits = append(field.fruits, fruitTexture{skin.GetTexture(name), skin.GetTexture(name + "-overlay")})
+	}
+
+	field.drop = fruitTexture{skin.GetTexture("fruit-drop"), skin.GetTexture("fruit-drop-overlay")}
+	field.bananas = fruitTexture{skin.GetTexture("fruit-bananas"), skin.GetTexture("fruit-bananas-overlay")}
+
+	field.catcherIdle = skin.GetTexture("fruit-catcher-idle")
+	if field.catcherIdle == nil {
+		field.catcherIdle = skin.GetTexture("fruit-ryuuta")
+	}
+
+	field.catcherKiai = skin.GetTexture("fruit-catcher-kiai")
+	field.catcherFail = skin.GetTexture("fruit-catcher-fail")
+
+	return field
+}
+
+func (field *CatchPlayfield) Update(time float64) {
+	// Catcher shows its fail texture after missing a fruit or a droplet
+	for field.judgedObject < len(field.objects) && field.ruleset.GetObjectState(field.cursor, field.objects[field.judgedObject]) != catch.Pending {
+		object := field.objects[field.judgedObject]
+
+		if object.IsPalpable() && field.ruleset.GetObjectState(field.cursor, object) == catch.Missed {
+			field.lastMiss = float64(object.StartTime)
+		}
+
+		field.judgedObject++
+	}
+
+	for field.firstObject < len(field.objects) && float64(field.objects[field.firstObject].StartTime) < time-field.preempt {
+		field.firstObject++
+	}
+}
+
+// toScreen converts osu!catch playfield coordinates to screen ones
+func (field *CatchPlayfield) toScreen(x, y float64) vector.Vector2d {
+	return vector.NewVec2d(field.left+x*field.scale, field.top+y*field.scale)
+}
+
+func (field *CatchPlayfield) Draw(batch *batch.QuadBatch, time float64, alpha float64) {
+	if alpha < 0.001 {
+		return
+	}
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	pixel := graphics.Pixel.GetRegion()
+
+	for i := len(field.objects) - 1; i >= field.firstObject; i-- {
+		field.drawObject(batch, field.objects[i], time, pixel)
+	}
+
+	field.drawCatcher(batch, time, pixel)
+
+	batch.End()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func (field *CatchPlayfield) drawObject(batch *batch.QuadBatch, object *catch.Object, time float64, pixel texture.TextureRegion) {
+	timeLeft := float64(object.StartTime) - time
+	if timeLeft > field.preempt {
+		return
+	}
+
+	if field.ruleset.GetObjectState(field.cursor, object) == catch.Caught {
+		return
+	}
+
+	y := catcherY * (1 - timeLeft/field.preempt)
+	if y > 384+field.fruitSize {
+		return
+	}
+
+	position := field.toScreen(object.X, y)
+
+	col := comboColor(object.Source)
+	size := field.fruitSize
+	tex := field.drop
+
+	switch object.Type {
+	case catch.Fruit:
+		tex = field.fruits[object.VisualIndex%len(field.fruits)]
+	case catch.Droplet:
+		size *= 0.8
+	case catch.TinyDroplet:
+		size *= 0.4
+	case catch.Banana:
+		tex = field.bananas
+		col = bananaColor
+	}
+
+	size *= field.scale
+
+	if object.HyperDashTarget != nil {
+		glow := hyperDashColor
+		glow.A = 0.6
+
+		field.drawFruit(batch, tex, position, size*1.2, glow, pixel, false)
+	}
+
+	field.drawFruit(batch, tex, position, size, col, pixel, true)
+}
+
+// drawFruit draws a fruit texture tinted with col and its untinted overlay. Falls back to a colored quad if skin doesn't have the texture.
+func (field *CatchPlayfield) drawFruit(batch *batch.QuadBatch, tex fruitTexture, position vector.Vector2d, size float64, col color2.Color, pixel texture.TextureRegion, overlay bool) {
+	if tex.base == nil {
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(size*0.7, size*0.7), false, false, 0, col, false, pixel)
+		return
+	}
+
+	scale := size / float64(tex.base.Width)
+	batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, col, false, *tex.base)
+
+	if overlay && tex.overlay != nil {
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *tex.overlay)
+	}
+}
+
+func (field *CatchPlayfield) drawCatcher(batch *batch.QuadBatch, time float64, pixel texture.TextureRegion) {
+	state := field.ruleset.GetCatcher(field.cursor)
+
+	position := field.toScreen(state.X, catcherY)
+	width := 106.75 * field.catcherScale * field.scale
+
+	col := color2.NewL(1)
+	if state.HyperDashing {
+		col = hyperDashColor
+	}
+
+	tex := field.catcherIdle
+
+	if time-field.lastMiss < 500 && field.catcherFail != nil {
+		tex = field.catcherFail
+	} else if field.ruleset.GetBeatMap().Timings.GetPoint(time).Kiai && field.catcherKiai != nil {
+		tex = field.catcherKiai
+	}
+
+	if tex == nil {
+		if state.Dashing || state.HyperDashing {
+			trail := col
+			trail.A = 0.3
+
+			batch.DrawStObject(position.AddS(-float64(facingSign(state.FacingLeft))*width*0.3, 0), bmath.Origin.TopCentre, vector.NewVec2d(width, width*0.25), false, false, 0, trail, false, pixel)
+		}
+
+		batch.DrawStObject(position, bmath.Origin.TopCentre, vector.NewVec2d(width, width*0.25), false, false, 0, col, false, pixel)
+
+		return
+	}
+
+	scale := width / float64(tex.Width)
+
+	if state.Dashing || state.HyperDashing {
+		trail := col
+		trail.A = 0.3
+
+		batch.DrawStObject(position.AddS(-float64(facingSign(state.FacingLeft))*width*0.3, 0), bmath.Origin.TopCentre, vector.NewVec2d(scale, scale), state.FacingLeft, false, 0, trail, false, *tex)
+	}
+
+	batch.DrawStObject(position, bmath.Origin.TopCentre, vector.NewVec2d(scale, scale), state.FacingLeft, false, 0, col, false, *tex)
+}
+
+// facingSign returns -1 for catcher facing left and 1 for facing right
+func facingSign(left bool) int {
+	if left {
+		return -1
+	}
+
+	return 1
+}
+
+// comboColor returns combo color of the standard object a fruit was generated from
+func comboColor(source objects.IHitObject) color2.Color {
+	colors := skin.GetColors()
+	if len(colors) == 0 {
+		return color2.NewL(1)
+	}
+
+	comboSet := int64(0)
+
+	switch o := source.(type) {
+	case *objects.Circle:
+		comboSet = o.ComboSet
+	case *objects.Slider:
+		comboSet = o.ComboSet
+	}
+
+	return colors[comboSet%int64(len(colors))]
+}
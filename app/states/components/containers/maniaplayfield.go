@@ -0,0 +1,266 @@
+package containers
+
+import (
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"log"
+	"strconv"
+)
+
+type maniaColumn struct {
+	x float64
+
+	note, head, body, tail *texture.TextureRegion
+	key, keyDown           *texture.TextureRegion
+
+	color color2.Color
+}
+
+// ManiaPlayfield draws osu!mania stage, notes and keys in 1080p UI coordinates
+type ManiaPlayfield struct {
+	ruleset *mania.ManiaRuleSet
+	cursor  *graphics.Cursor
+	notes   []*mania.Note
+
+	columns []*maniaColumn
+
+	stageLeft, stageRight, stageHint *texture.TextureRegion
+
+	left, width, height float64
+	columnWidth         float64
+	hitPosition         float64
+
+	firstNote int
+}
+
+func NewManiaPlayfield(ruleset *mania.ManiaRuleSet, cursor *graphics.Cursor, scaledWidth, scaledHeight float64) *ManiaPlayfield {
+	log.Println("Creating osu!mania playfield...")
+
+	keys := ruleset.GetKeyCount()
+
+	field := &ManiaPlayfield{
+		ruleset:     ruleset,
+		cursor:      cursor,
+		notes:       ruleset.GetNotes(),
+		columnWidth: settings.Gameplay.Mania.ColumnWidth,
+		hitPosition: settings.Gameplay.Mania.HitPosition,
+		height:      scaledHeight,
+	}
+
+	field.width = field.columnWidth * float64(keys)
+	field.left = (scaledWidth - field.width) / 2
+
+	field.stageLeft = skin.GetTexture("mania-stage-left")
+	field.stageRight = skin.GetTexture("mania-stage-right")
+	field.stageHint = skin.GetTexture("mania-stage-hint")
+
+	for i := 0; i < keys; i++ {
+		suffix := columnType(i, keys)
+
+		column := &maniaColumn{
+			x:       field.left + (float64(i)+0.5)*field.columnWidth,
+			note:    skin.GetTexture("mania-note" + suffix),
+			head:    skin.GetTexture("mania-note" + suffix + "H"),
+			body:    skin.GetTexture("mania-note" + suffix + "L"),
+			tail:    skin.GetTexture("mania-note" + suffix + "T"),
+			key:     skin.GetTexture("mania-key" + suffix),
+			keyDown: skin.GetTexture("mania-key" + suffix + "D"),
+		}
+
+		if column.head == nil {
+			column.head = column.note
+		}
+
+		switch suffix {
+		case "1":
+			column.color = color2.NewL(1)
+		case "2":
+			column.color = color2.NewRGB(0.4, 0.7, 1)
+		default:
+			column.color = color2.NewRGB(1, 0.85, 0.3)
+		}
+
+		field.columns = append(field.columns, column)
+	}
+
+	return field
+}
+
+// columnType returns skin suffix of a column, columns are mirrored from the edges and the middle one of odd layouts is special
+func columnType(column, keys int) string {
+	if keys%2 == 1 && column == keys/2 {
+		return "S"
+	}
+
+	fromEdge := bmath.MinI(column, keys-1-column)
+
+	return strconv.Itoa(fromEdge%2 + 1)
+}
+
+func (field *ManiaPlayfield) Update(time float64) {
+	for field.firstNote < len(field.notes) && float64(field.notes[field.firstNote].EndTime) < time-settings.Gameplay.Mania.ScrollTime {
+		field.firstNote++
+	}
+}
+
+// position returns Y coordinate of a note at given time
+func (field *ManiaPlayfield) position(noteTime, time float64) float64 {
+	return field.hitPosition - (noteTime-time)/settings.Gameplay.Mania.ScrollTime*field.hitPosition
+}
+
+func (field *ManiaPlayfield) Draw(batch *batch.QuadBatch, time float64, alpha float64) {
+	if alpha < 0.001 {
+		return
+	}
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	pixel := graphics.Pixel.GetRegion()
+
+	batch.DrawStObject(vector.NewVec2d(field.left, 0), bmath.Origin.TopLeft, vector.NewVec2d(field.width, field.height), false, false, 0, color2.NewLA(0, 0.8), false, pixel)
+
+	if field.stageLeft != nil {
+		scale := field.height / float64(field.stageLeft.Height)
+		batch.DrawStObject(vector.NewVec2d(field.left, 0), bmath.Origin.TopRight, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *field.stageLeft)
+	}
+
+	if field.stageRight != nil {
+		scale := field.height / float64(field.stageRight.Height)
+		batch.DrawStObject(vector.NewVec2d(field.left+field.width, 0), bmath.Origin.TopLeft, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *field.stageRight)
+	}
+
+	field.drawKeys(batch, pixel)
+
+	if field.stageHint != nil {
+		scale := field.width / float64(field.stageHint.Width)
+		batch.DrawStObject(vector.NewVec2d(field.left+field.width/2, field.hitPosition), bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *field.stageHint)
+	} else {
+		batch.DrawStObject(vector.NewVec2d(field.left, field.hitPosition), bmath.Origin.CentreLeft, vector.NewVec2d(field.width, 4), false, false, 0, color2.NewLA(1, 0.6), false, pixel)
+	}
+
+	field.drawNotes(batch, time, pixel)
+
+	batch.End()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func (field *ManiaPlayfield) drawKeys(batch *batch.QuadBatch, pixel texture.TextureRegion) {
+	if !settings.Gameplay.Mania.ShowKeys {
+		return
+	}
+
+	for i, column := range field.columns {
+		pressed := field.ruleset.IsPressed(field.cursor, i)
+
+		tex := column.key
+		if pressed && column.keyDown != nil {
+			tex = column.keyDown
+		}
+
+		if tex != nil {
+			scale := field.columnWidth / float64(tex.Width)
+			batch.DrawStObject(vector.NewVec2d(column.x, field.height), bmath.Origin.BottomCentre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *tex)
+
+			continue
+		}
+
+		col := column.color
+		col.A = 0.25
+
+		if pressed {
+			col.A = 0.7
+		}
+
+		batch.DrawStObject(vector.NewVec2d(column.x, field.height), bmath.Origin.BottomCentre, vector.NewVec2d(field.columnWidth-4, field.height-field.hitPosition), false, false, 0, col, false, pixel)
+	}
+}
+
+func (field *ManiaPlayfield) drawNotes(batch *batch.QuadBatch, time float64, pixel texture.TextureRegion) {
+	for i := field.firstNote; i < len(field.notes); i++ {
+		note := field.notes[i]
+
+		if float64(note.StartTime) > time+settings.Gameplay.Mania.ScrollTime {
+			break
+		}
+
+		state := field.ruleset.GetNoteState(field.cursor, note)
+		if state == mania.Hit {
+			continue
+		}
+
+		column := field.columns[note.Column]
+
+		col := color2.NewL(1)
+		if state == mania.Missed {
+			col = color2.NewLA(0.4, 0.6)
+		}
+
+		yStart := field.position(float64(note.StartTime), time)
+		if state == mania.Holding {
+			yStart = field.hitPosition
+		}
+
+		if note.Hold {
+			yEnd := field.position(float64(note.EndTime), time)
+
+			if yEnd > field.height {
+				continue
+			}
+
+			field.drawPart(batch, column.body, pixel, column, vector.NewVec2d(column.x, yStart), bmath.Origin.BottomCentre, yStart-yEnd, col, 0.6)
+
+			if column.tail != nil {
+				field.drawPart(batch, column.tail, pixel, column, vector.NewVec2d(column.x, yEnd), bmath.Origin.BottomCentre, 0, col, 1)
+			}
+
+			field.drawPart(batch, column.head, pixel, column, vector.NewVec2d(column.x, yStart), bmath.Origin.BottomCentre, 0, col, 1)
+
+			continue
+		}
+
+		if yStart > field.height+field.columnWidth {
+			continue
+		}
+
+		field.drawPart(batch, column.note, pixel, column, vector.NewVec2d(column.x, yStart), bmath.Origin.BottomCentre, 0, col, 1)
+	}
+}
+
+// drawPart draws a note texture scaled to column width, stretched to length if it's above 0. Falls back to a colored quad if skin doesn't have the texture.
+func (field *ManiaPlayfield) drawPart(batch *batch.QuadBatch, tex *texture.TextureRegion, pixel texture.TextureRegion, column *maniaColumn, position, origin vector.Vector2d, length float64, col color2.Color, fallbackAlpha float32) {
+	if tex != nil {
+		scale := field.columnWidth / float64(tex.Width)
+
+		scaleY := scale
+		if length > 0 {
+			scaleY = length / float64(tex.Height)
+		}
+
+		batch.DrawStObject(position, origin, vector.NewVec2d(scale, scaleY), false, false, 0, col, false, *tex)
+
+		return
+	}
+
+	height := field.columnWidth / 3
+	if length > 0 {
+		height = length
+	}
+
+	fCol := column.color
+	fCol.R *= col.R
+	fCol.G *= col.G
+	fCol.B *= col.B
+	fCol.A = col.A * fallbackAlpha
+
+	batch.DrawStObject(position, origin, vector.NewVec2d(field.columnWidth-4, height), false, false, 0, fCol, false, pixel)
+}
@@ -48,6 +48,22 @@ func NewHitObjectContainer(beatMap *beatmap.BeatMap) *HitObjectContainer {
 	return container
 }
 
+// Rewind removes drawn objects and queues again objects starting at or after the given time, it has to be called after BeatMap.Rewind
+func (container *HitObjectContainer) Rewind(time float64) {
+	container.objectQueue = container.objectQueue[:0]
+
+	for _, o := range container.beatMap.HitObjects {
+		if o.GetStartTime() >= time {
+			container.objectQueue = append(container.objectQueue, o)
+		}
+	}
+
+	container.renderables = container.renderables[:0]
+
+	container.spriteManager = sprite.NewSpriteManager()
+	container.createFollowPoints()
+}
+
 func (container *HitObjectContainer) createFollowPoints() {
 	const (
 		preEmpt  = 800.0
@@ -0,0 +1,9 @@
+package containers
+
+import "github.com/wieku/danser-go/framework/graphics/batch"
+
+// Playfield draws objects of rulesets other than osu!standard in 1080p UI coordinates
+type Playfield interface {
+	Update(time float64)
+	Draw(batch *batch.QuadBatch, time float64, alpha float64)
+}
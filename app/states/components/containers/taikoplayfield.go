@@ -0,0 +1,342 @@
+package containers
+
+import (
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"log"
+	"math"
+)
+
+const (
+	taikoLaneHeight  = 200.0
+	taikoLeftWidth   = 250.0
+	taikoHitOffset   = 110.0
+	taikoSmallSize   = 100.0
+	taikoBigSize     = 150.0
+	taikoBaseScroll  = 140.0
+	taikoKeyFadeTime = 100.0
+)
+
+var (
+	taikoDonColor  = color2.NewIRGB(235, 69, 44)
+	taikoKatColor  = color2.NewIRGB(67, 142, 172)
+	taikoRollColor = color2.NewIRGB(252, 184, 6)
+)
+
+// TaikoPlayfield draws osu!taiko lane, drum and scrolling objects in 1080p UI coordinates
+type TaikoPlayfield struct {
+	ruleset *taiko.TaikoRuleSet
+	cursor  *graphics.Cursor
+	objects []*taiko.Object
+
+	barLeft, barRight       *texture.TextureRegion
+	drumInner, drumOuter    *texture.TextureRegion
+	target                  *texture.TextureRegion
+	small, smallOverlay     *texture.TextureRegion
+	big, bigOverlay         *texture.TextureRegion
+	rollMiddle, rollEnd     *texture.TextureRegion
+	tick                    *texture.TextureRegion
+	swellWarning, swellRing *texture.TextureRegion
+
+	width, centre, scale float64
+	hitX                 float64
+
+	// Last time each drum part was pressed, in order of taiko.DonLeft, KatLeft, DonRight and KatRight
+	keyTimes [4]float64
+	keys     uint32
+
+	firstObject int
+}
+
+func NewTaikoPlayfield(ruleset *taiko.TaikoRuleSet, cursor *graphics.Cursor, scaledWidth, _ float64) *TaikoPlayfield {
+	log.Println("Creating osu!taiko playfield...")
+
+	field := &TaikoPlayfield{
+		ruleset: ruleset,
+		cursor:  cursor,
+		objects: ruleset.GetObjects(),
+		width:   scaledWidth,
+		centre:  settings.Gameplay.Taiko.PositionY,
+		scale:   settings.Gameplay.Taiko.Scale,
+	}
+
+	field.hitX = (taikoLeftWidth + taikoHitOffset) * field.scale
+
+	field.barLeft = skin.GetTexture("taiko-bar-left")
+	field.barRight = skin.GetTexture("taiko-bar-right")
+	field.drumInner = skin.GetTexture("taiko-drum-inner")
+	field.drumOuter = skin.GetTexture("taiko-drum-outer")
+	field.target = skin.GetTexture("approachcircle")
+	field.rollMiddle = skin.GetTexture("taiko-roll-middle")
+	field.rollEnd = skin.GetTexture("taiko-roll-end")
+	field.tick = skin.GetTexture("sliderscorepoint")
+	field.swellWarning = skin.GetTexture("spinner-warning")
+	field.swellRing = skin.GetTexture("spinner-approachcircle")
+
+	field.small, field.smallOverlay = skin.GetTexture("taikohitcircle"), skin.GetTexture("taikohitcircleoverlay")
+	if field.small == nil {
+		field.small, field.smallOverlay = skin.GetTexture("hitcircle"), skin.GetTexture("hitcircleoverlay")
+	}
+
+	field.big, field.bigOverlay = skin.GetTexture("taikobigcircle"), skin.GetTexture("taikobigcircleoverlay")
+	if field.big == nil {
+		field.big, field.bigOverlay = field.small, field.smallOverlay
+	}
+
+	for i := range field.keyTimes {
+		field.keyTimes[i] = math.Inf(-1)
+	}
+
+	return field
+}
+
+func (field *TaikoPlayfield) Update(time float64) {
+	keys := field.ruleset.GetKeys(field.cursor)
+
+	for i := range field.keyTimes {
+		if key := uint32(1) << uint(i); keys&key > 0 && field.keys&key == 0 {
+			field.keyTimes[i] = time
+		}
+	}
+
+	field.keys = keys
+
+	for field.firstObject < len(field.objects) && field.position(field.objects[field.firstObject], float64(field.objects[field.firstObject].EndTime), time) < -taikoBigSize*field.scale {
+		field.firstObject++
+	}
+}
+
+// velocity returns scroll speed of an object in pixels per millisecond
+func (field *TaikoPlayfield) velocity(object *taiko.Object) float64 {
+	return settings.Gameplay.Taiko.ScrollSpeed * field.ruleset.GetBeatMap().Timings.SliderMult * taikoBaseScroll * field.scale / object.BeatLength
+}
+
+// position returns X coordinate of a point of the object at objectTime
+func (field *TaikoPlayfield) position(object *taiko.Object, objectTime, time float64) float64 {
+	return field.hitX + (objectTime-time)*field.velocity(object)
+}
+
+func (field *TaikoPlayfield) Draw(batch *batch.QuadBatch, time float64, alpha float64) {
+	if alpha < 0.001 {
+		return
+	}
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	pixel := graphics.Pixel.GetRegion()
+
+	height := taikoLaneHeight * field.scale
+	leftWidth := taikoLeftWidth * field.scale
+
+	if field.barRight != nil {
+		scale := height / float64(field.barRight.Height)
+		batch.DrawStObject(vector.NewVec2d(leftWidth, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d((field.width-leftWidth)/float64(field.barRight.Width), scale), false, false, 0, color2.NewL(1), false, *field.barRight)
+	} else {
+		batch.DrawStObject(vector.NewVec2d(leftWidth, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d(field.width-leftWidth, height), false, false, 0, color2.NewLA(0, 0.8), false, pixel)
+	}
+
+	field.drawTarget(batch, pixel)
+
+	for i := len(field.objects) - 1; i >= field.firstObject; i-- {
+		field.drawObject(batch, field.objects[i], time, pixel)
+	}
+
+	if field.barLeft != nil {
+		scale := height / float64(field.barLeft.Height)
+		batch.DrawStObject(vector.NewVec2d(0, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d(scale, scale), false, false, 0, color2.NewL(1), false, *field.barLeft)
+	} else {
+		batch.DrawStObject(vector.NewVec2d(0, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d(leftWidth, height), false, false, 0, color2.NewRGBA(0.15, 0.15, 0.2, 1), false, pixel)
+	}
+
+	field.drawDrum(batch, time, pixel)
+
+	batch.End()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func (field *TaikoPlayfield) drawTarget(batch *batch.QuadBatch, pixel texture.TextureRegion) {
+	position := vector.NewVec2d(field.hitX, field.centre)
+
+	if field.target != nil {
+		scale := taikoSmallSize * field.scale / float64(field.target.Width)
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewLA(1, 0.5), false, *field.target)
+
+		return
+	}
+
+	batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(4, taikoLaneHeight*field.scale), false, false, 0, color2.NewLA(1, 0.4), false, pixel)
+}
+
+// drawDrum draws both halves of the drum, highlighting parts that were recently hit
+func (field *TaikoPlayfield) drawDrum(batch *batch.QuadBatch, time float64, pixel texture.TextureRegion) {
+	drumCentre := vector.NewVec2d(taikoLeftWidth*field.scale/2, field.centre)
+	size := taikoLaneHeight * field.scale * 0.8
+
+	for i, keyTime := range field.keyTimes {
+		key := uint32(1) << uint(i)
+
+		highlight := 1 - (time-keyTime)/taikoKeyFadeTime
+		if field.keys&key > 0 {
+			highlight = 1
+		}
+
+		if highlight <= 0 {
+			continue
+		}
+
+		kat := key&(taiko.KatLeft|taiko.KatRight) > 0
+		right := key&(taiko.DonRight|taiko.KatRight) > 0
+
+		origin := bmath.Origin.CentreRight
+		if right {
+			origin = bmath.Origin.CentreLeft
+		}
+
+		tex := field.drumInner
+		if kat {
+			tex = field.drumOuter
+		}
+
+		if tex != nil {
+			scale := size / float64(tex.Height)
+			batch.DrawStObject(drumCentre, origin, vector.NewVec2d(scale, scale), right, false, 0, color2.NewLA(1, float32(highlight)), false, *tex)
+
+			continue
+		}
+
+		col := taikoDonColor
+		partSize := size * 0.6
+
+		if kat {
+			col = taikoKatColor
+			partSize = size
+		}
+
+		col.A = float32(highlight * 0.8)
+
+		batch.DrawStObject(drumCentre, origin, vector.NewVec2d(partSize/2, partSize), false, false, 0, col, false, pixel)
+	}
+}
+
+func (field *TaikoPlayfield) drawObject(batch *batch.QuadBatch, object *taiko.Object, time float64, pixel texture.TextureRegion) {
+	state := field.ruleset.GetObjectState(field.cursor, object)
+
+	startX := field.position(object, float64(object.StartTime), time)
+	if startX > field.width+taikoBigSize*field.scale {
+		return
+	}
+
+	alpha := float32(1)
+	if state == taiko.Missed {
+		alpha = 0.5
+	}
+
+	size := taikoSmallSize * field.scale
+	if object.Big {
+		size = taikoBigSize * field.scale
+	}
+
+	switch object.Type {
+	case taiko.Don, taiko.Kat:
+		if state == taiko.Hit {
+			return
+		}
+
+		col := taikoDonColor
+		if object.Type == taiko.Kat {
+			col = taikoKatColor
+		}
+
+		field.drawCircle(batch, object.Big, vector.NewVec2d(startX, field.centre), size, col, alpha, pixel)
+	case taiko.DrumRoll:
+		endX := field.position(object, float64(object.EndTime), time)
+
+		col := taikoRollColor
+		col.A = alpha
+
+		if field.rollMiddle != nil {
+			batch.DrawStObject(vector.NewVec2d(startX, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d((endX-startX)/float64(field.rollMiddle.Width), size/float64(field.rollMiddle.Height)), false, false, 0, col, false, *field.rollMiddle)
+		} else {
+			batch.DrawStObject(vector.NewVec2d(startX, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d(endX-startX, size*0.8), false, false, 0, col, false, pixel)
+		}
+
+		if field.rollEnd != nil {
+			scale := size / float64(field.rollEnd.Height)
+			batch.DrawStObject(vector.NewVec2d(endX, field.centre), bmath.Origin.CentreLeft, vector.NewVec2d(scale, scale), false, false, 0, col, false, *field.rollEnd)
+		}
+
+		for _, tick := range object.Ticks {
+			if float64(tick) < time {
+				continue
+			}
+
+			position := vector.NewVec2d(field.position(object, float64(tick), time), field.centre)
+
+			if field.tick != nil {
+				scale := size * 0.25 / float64(field.tick.Width)
+				batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewLA(1, alpha), false, *field.tick)
+			} else {
+				batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(size*0.15, size*0.15), false, false, 0, color2.NewLA(1, alpha), false, pixel)
+			}
+		}
+
+		field.drawCircle(batch, object.Big, vector.NewVec2d(startX, field.centre), size, taikoRollColor, alpha, pixel)
+	case taiko.Swell:
+		if state != taiko.Pending {
+			return
+		}
+
+		position := vector.NewVec2d(math.Max(startX, field.hitX), field.centre)
+
+		if time >= float64(object.StartTime) {
+			progress := float64(field.ruleset.GetObjectHits(field.cursor, object)) / float64(object.RequiredHits)
+			ringSize := size * (3 - 2*progress)
+
+			if field.swellRing != nil {
+				scale := ringSize / float64(field.swellRing.Width)
+				batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewLA(1, 0.8), false, *field.swellRing)
+			} else {
+				batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(ringSize, ringSize), false, false, 0, color2.NewLA(1, 0.2), false, pixel)
+			}
+		}
+
+		field.drawCircle(batch, false, position, size, taikoRollColor, alpha, pixel)
+
+		if field.swellWarning != nil {
+			scale := size * 0.6 / float64(field.swellWarning.Width)
+			batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewLA(1, alpha), false, *field.swellWarning)
+		}
+	}
+}
+
+// drawCircle draws a taiko hit circle with its overlay. Falls back to a colored quad if skin doesn't have the texture.
+func (field *TaikoPlayfield) drawCircle(batch *batch.QuadBatch, big bool, position vector.Vector2d, size float64, col color2.Color, alpha float32, pixel texture.TextureRegion) {
+	tex, overlay := field.small, field.smallOverlay
+	if big {
+		tex, overlay = field.big, field.bigOverlay
+	}
+
+	col.A = alpha
+
+	if tex == nil {
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(size*0.8, size*0.8), false, false, 0, col, false, pixel)
+		return
+	}
+
+	scale := size / float64(tex.Width)
+	batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, col, false, *tex)
+
+	if overlay != nil {
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(scale, scale), false, false, 0, color2.NewLA(1, alpha), false, *overlay)
+	}
+}
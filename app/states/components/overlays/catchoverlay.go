@@ -0,0 +1,115 @@
+package overlays
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+// CatchOverlay shows score, accuracy, combo and health of an osu!catch play
+type CatchOverlay struct {
+	ruleset *catch.CatchRuleSet
+	cursor  *graphics.Cursor
+
+	scoreFont *font.Font
+	comboFont *font.Font
+
+	audioDisabled bool
+
+	ScaledWidth  float64
+	ScaledHeight float64
+}
+
+func NewCatchOverlay(ruleset *catch.CatchRuleSet, cursor *graphics.Cursor) *CatchOverlay {
+	overlay := &CatchOverlay{
+		ruleset:      ruleset,
+		cursor:       cursor,
+		scoreFont:    skin.GetFont("score"),
+		comboFont:    skin.GetFont("combo"),
+		ScaledHeight: 1080,
+	}
+
+	overlay.ScaledWidth = overlay.ScaledHeight * settings.Graphics.GetAspectRatio()
+
+	ruleset.SetListener(overlay.hitReceived)
+
+	return overlay
+}
+
+func (overlay *CatchOverlay) hitReceived(_ *graphics.Cursor, _ int64, object *catch.Object, result catch.HitResult) {
+	if overlay.audioDisabled || !result.IsHit() {
+		return
+	}
+
+	switch source := object.Source.(type) {
+	case *objects.Circle:
+		source.PlaySound()
+	case *objects.Slider:
+		if object.Type == catch.Fruit {
+			source.PlayEdgeSample(object.EdgeIndex)
+		} else if object.Type == catch.Droplet {
+			source.PlayTick()
+		}
+	}
+}
+
+func (overlay *CatchOverlay) Update(_ float64) {}
+
+func (overlay *CatchOverlay) DrawBeforeObjects(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *CatchOverlay) DrawNormal(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *CatchOverlay) DrawHUD(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	batch.ResetTransform()
+
+	accuracy, _, score, _ := overlay.ruleset.GetResults(overlay.cursor)
+	combo := overlay.ruleset.GetCombo(overlay.cursor)
+
+	if settings.Gameplay.HpBar.Show {
+		hpAlpha := alpha * settings.Gameplay.HpBar.Opacity
+		hpWidth := overlay.ScaledWidth * 0.4 * settings.Gameplay.HpBar.Scale
+		hpHeight := 12 * settings.Gameplay.HpBar.Scale
+
+		batch.DrawStObject(vector.NewVec2d(0, 0), bmath.Origin.TopLeft, vector.NewVec2d(hpWidth, hpHeight), false, false, 0, color2.NewLA(0, float32(0.6*hpAlpha)), false, graphics.Pixel.GetRegion())
+		batch.DrawStObject(vector.NewVec2d(0, 0), bmath.Origin.TopLeft, vector.NewVec2d(hpWidth*overlay.ruleset.GetHP(overlay.cursor), hpHeight), false, false, 0, color2.NewRGBA(0.4, 1, 0.4, float32(hpAlpha)), false, graphics.Pixel.GetRegion())
+	}
+
+	if settings.Gameplay.Score.Show {
+		scoreAlpha := alpha * settings.Gameplay.Score.Opacity
+		scoreSize := overlay.scoreFont.GetSize() * settings.Gameplay.Score.Scale * 0.96
+
+		batch.SetColor(1, 1, 1, scoreAlpha)
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, 0, bmath.Origin.TopRight, scoreSize, true, fmt.Sprintf("%08d", score))
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, scoreSize+4.8, bmath.Origin.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", accuracy))
+	}
+
+	if settings.Gameplay.ComboCounter.Show && combo > 0 {
+		comboSize := overlay.comboFont.GetSize() * settings.Gameplay.ComboCounter.Scale
+
+		batch.SetColor(1, 1, 1, alpha*settings.Gameplay.ComboCounter.Opacity)
+		overlay.comboFont.DrawOrigin(batch, 0, overlay.ScaledHeight, bmath.Origin.BottomLeft, comboSize, false, fmt.Sprintf("%dx", combo))
+	}
+
+	batch.SetColor(1, 1, 1, 1)
+	batch.ResetTransform()
+}
+
+func (overlay *CatchOverlay) IsBroken(_ *graphics.Cursor) bool {
+	return false
+}
+
+func (overlay *CatchOverlay) DisableAudioSubmission(b bool) {
+	overlay.audioDisabled = b
+}
+
+func (overlay *CatchOverlay) ShouldDrawHUDBeforeCursor() bool {
+	return true
+}
@@ -103,6 +103,7 @@ type KnockoutOverlay struct {
 	font         *font.Font
 	players      map[string]*knockoutPlayer
 	playersArray []*knockoutPlayer
+	startOrder   []*knockoutPlayer
 	deathBubbles []*bubble
 	names        map[*graphics.Cursor]string
 	lastTime     float64
@@ -154,6 +155,8 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 		}
 	}
 
+	overlay.startOrder = append([]*knockoutPlayer(nil), overlay.playersArray...)
+
 	replayController.GetRuleset().SetListener(overlay.hitReceived)
 
 	sortFunc := func(time int64, number int64, instantSort bool) {
@@ -293,6 +296,38 @@ func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64,
 	}
 }
 
+// Rewind brings all players back to the state before the beatmap started, results are received again from the ruleset
+func (overlay *KnockoutOverlay) Rewind(time float64) {
+	overlay.lastTime = time
+	overlay.deathBubbles = overlay.deathBubbles[:0]
+
+	copy(overlay.playersArray, overlay.startOrder)
+
+	for i, player := range overlay.playersArray {
+		*player = knockoutPlayer{
+			fade:         animation.NewGlider(1),
+			slide:        animation.NewGlider(0),
+			height:       animation.NewGlider(overlay.ScaledHeight * 0.9 * 1.04 / (51)),
+			index:        animation.NewGlider(float64(i)),
+			scoreDisp:    animation.NewGlider(0),
+			ppDisp:       animation.NewGlider(0),
+			maxCombo:     player.maxCombo,
+			scores:       make([]int64, len(player.scores)),
+			pps:          make([]float64, len(player.pps)),
+			lastHit:      osu.Hit300,
+			fadeHit:      animation.NewGlider(0),
+			scaleHit:     animation.NewGlider(0),
+			name:         player.name,
+			oldIndex:     player.oldIndex,
+			currentIndex: i,
+		}
+
+		player.index.SetEasing(easing.InOutQuad)
+	}
+
+	discord.UpdateKnockout(len(overlay.playersArray), len(overlay.playersArray))
+}
+
 func (overlay *KnockoutOverlay) Update(time float64) {
 	delta := time - overlay.lastTime
 
@@ -0,0 +1,163 @@
+package overlays
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const maniaJudgementTime = 300.0
+
+var maniaJudgementTextures = []string{"mania-hit0", "mania-hit50", "mania-hit100", "mania-hit200", "mania-hit300", "mania-hit300g"}
+
+type maniaSoundObject interface {
+	PlaySound()
+}
+
+// ManiaOverlay shows score, accuracy, combo, health and last judgement of an osu!mania play
+type ManiaOverlay struct {
+	ruleset *mania.ManiaRuleSet
+	cursor  *graphics.Cursor
+
+	scoreFont *font.Font
+	comboFont *font.Font
+	textFont  *font.Font
+
+	judgements []*texture.TextureRegion
+
+	lastResult     mania.HitResult
+	lastResultTime float64
+	time           float64
+
+	audioDisabled bool
+
+	ScaledWidth  float64
+	ScaledHeight float64
+}
+
+func NewManiaOverlay(ruleset *mania.ManiaRuleSet, cursor *graphics.Cursor) *ManiaOverlay {
+	overlay := &ManiaOverlay{
+		ruleset:        ruleset,
+		cursor:         cursor,
+		scoreFont:      skin.GetFont("score"),
+		comboFont:      skin.GetFont("combo"),
+		textFont:       font.GetFont("Exo 2 Bold"),
+		lastResultTime: math.Inf(-1),
+		ScaledHeight:   1080,
+	}
+
+	overlay.ScaledWidth = overlay.ScaledHeight * settings.Graphics.GetAspectRatio()
+
+	for _, name := range maniaJudgementTextures {
+		overlay.judgements = append(overlay.judgements, skin.GetTexture(name))
+	}
+
+	ruleset.SetListener(overlay.hitReceived)
+
+	return overlay
+}
+
+func (overlay *ManiaOverlay) hitReceived(_ *graphics.Cursor, time int64, note *mania.Note, result mania.HitResult, head bool) {
+	overlay.lastResult = result
+	overlay.lastResultTime = float64(time)
+
+	if head && result != mania.Miss && !overlay.audioDisabled {
+		if sound, ok := note.Object.(maniaSoundObject); ok {
+			sound.PlaySound()
+		}
+	}
+}
+
+func (overlay *ManiaOverlay) Update(time float64) {
+	overlay.time = time
+}
+
+func (overlay *ManiaOverlay) DrawBeforeObjects(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *ManiaOverlay) DrawNormal(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *ManiaOverlay) DrawHUD(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	batch.ResetTransform()
+
+	accuracy, _, score, _ := overlay.ruleset.GetResults(overlay.cursor)
+	combo := overlay.ruleset.GetCombo(overlay.cursor)
+
+	stageWidth := settings.Gameplay.Mania.ColumnWidth * float64(overlay.ruleset.GetKeyCount())
+	centre := overlay.ScaledWidth / 2
+
+	if settings.Gameplay.HpBar.Show {
+		hpAlpha := alpha * settings.Gameplay.HpBar.Opacity
+		hpHeight := overlay.ScaledHeight * 0.6 * settings.Gameplay.HpBar.Scale
+		hpWidth := 12 * settings.Gameplay.HpBar.Scale
+		hpX := centre + stageWidth/2 + 8
+
+		batch.DrawStObject(vector.NewVec2d(hpX, overlay.ScaledHeight), bmath.Origin.BottomLeft, vector.NewVec2d(hpWidth, hpHeight), false, false, 0, color2.NewLA(0, float32(0.6*hpAlpha)), false, graphics.Pixel.GetRegion())
+		batch.DrawStObject(vector.NewVec2d(hpX, overlay.ScaledHeight), bmath.Origin.BottomLeft, vector.NewVec2d(hpWidth, hpHeight*overlay.ruleset.GetHP(overlay.cursor)), false, false, 0, color2.NewRGBA(0.4, 1, 0.4, float32(hpAlpha)), false, graphics.Pixel.GetRegion())
+	}
+
+	if settings.Gameplay.Score.Show {
+		scoreAlpha := alpha * settings.Gameplay.Score.Opacity
+		scoreSize := overlay.scoreFont.GetSize() * settings.Gameplay.Score.Scale * 0.96
+
+		batch.SetColor(1, 1, 1, scoreAlpha)
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, 0, bmath.Origin.TopRight, scoreSize, true, fmt.Sprintf("%08d", score))
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, scoreSize+4.8, bmath.Origin.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", accuracy))
+	}
+
+	if settings.Gameplay.ComboCounter.Show && combo > 0 {
+		comboSize := overlay.comboFont.GetSize() * settings.Gameplay.ComboCounter.Scale
+
+		batch.SetColor(1, 1, 1, alpha*settings.Gameplay.ComboCounter.Opacity)
+		overlay.comboFont.DrawOrigin(batch, centre, overlay.ScaledHeight*0.3, bmath.Origin.Centre, comboSize, false, fmt.Sprintf("%d", combo))
+	}
+
+	overlay.drawJudgement(batch, centre, alpha)
+
+	batch.SetColor(1, 1, 1, 1)
+	batch.ResetTransform()
+}
+
+func (overlay *ManiaOverlay) drawJudgement(batch *batch.QuadBatch, centre, alpha float64) {
+	progress := (overlay.time - overlay.lastResultTime) / maniaJudgementTime
+	if progress < 0 || progress > 1 {
+		return
+	}
+
+	jAlpha := alpha * (1 - progress*progress)
+	scale := 0.8 + 0.2*math.Sin(math.Min(progress*4, 1)*math.Pi/2)
+
+	position := vector.NewVec2d(centre, overlay.ScaledHeight*0.45)
+
+	if tex := overlay.judgements[overlay.lastResult]; tex != nil {
+		batch.SetColor(1, 1, 1, jAlpha)
+		batch.SetScale(scale, scale)
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(1, 1), false, false, 0, color2.NewL(1), false, *tex)
+		batch.SetScale(1, 1)
+
+		return
+	}
+
+	batch.SetColor(1, 1, 1, jAlpha)
+	overlay.textFont.DrawOrigin(batch, position.X, position.Y, bmath.Origin.Centre, 40*scale, false, overlay.lastResult.String())
+}
+
+func (overlay *ManiaOverlay) IsBroken(_ *graphics.Cursor) bool {
+	return false
+}
+
+func (overlay *ManiaOverlay) DisableAudioSubmission(b bool) {
+	overlay.audioDisabled = b
+}
+
+func (overlay *ManiaOverlay) ShouldDrawHUDBeforeCursor() bool {
+	return true
+}
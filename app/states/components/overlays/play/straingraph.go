@@ -0,0 +1,99 @@
+package play
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/oppai"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/graphics/shape"
+	"math"
+)
+
+const (
+	strainGraphColumns = 200
+	strainGraphHeight  = 60.0
+)
+
+// StrainGraph draws difficulty of a beatmap over time at the bottom of the screen
+type StrainGraph struct {
+	values []float64
+
+	width, bottom float64
+}
+
+func NewStrainGraph(beatMap *beatmap.BeatMap, mods difficulty.Modifier, width, bottom float64) *StrainGraph {
+	graph := &StrainGraph{
+		values: make([]float64, strainGraphColumns+1),
+		width:  width,
+		bottom: bottom,
+	}
+
+	diff := beatMap.Diff.Clone()
+	diff.SetMods(mods)
+
+	hObjects := beatMap.HitObjects
+
+	// Use the same range as progress bar
+	startTime := hObjects[0].GetStartTime()
+	endTime := hObjects[len(hObjects)-1].GetEndTime()
+
+	peaks := make([]float64, strainGraphColumns+1)
+
+	for _, s := range oppai.CalculateStrains(hObjects, diff, false) {
+		index := bmath.ClampI(int((s.Time-startTime)/math.Max(endTime-startTime, 1)*strainGraphColumns), 0, strainGraphColumns)
+		peaks[index] = math.Max(peaks[index], s.Total)
+	}
+
+	maxValue := 0.0
+
+	// Light smoothing so single objects don't produce spikes
+	for i := range peaks {
+		prev := peaks[bmath.MaxI(i-1, 0)]
+		next := peaks[bmath.MinI(i+1, strainGraphColumns)]
+
+		graph.values[i] = (prev + peaks[i]*2 + next) / 4
+		maxValue = math.Max(maxValue, graph.values[i])
+	}
+
+	if maxValue > 0 {
+		for i := range graph.values {
+			graph.values[i] /= maxValue
+		}
+	}
+
+	return graph
+}
+
+func (graph *StrainGraph) Draw(renderer *shape.Renderer, progress, alpha float64) {
+	graphAlpha := settings.Gameplay.StrainGraph.Opacity * alpha
+
+	if graphAlpha < 0.001 || !settings.Gameplay.StrainGraph.Show {
+		return
+	}
+
+	height := strainGraphHeight * settings.Gameplay.StrainGraph.Scale
+	columnWidth := graph.width / strainGraphColumns
+
+	progressColumn := bmath.ClampF64(progress, 0, 1) * strainGraphColumns
+
+	renderer.Begin()
+
+	for i := 0; i < strainGraphColumns; i++ {
+		if float64(i) < progressColumn {
+			renderer.SetColor(1, 1, 1, 0.6*graphAlpha)
+		} else {
+			renderer.SetColor(1, 1, 1, 0.25*graphAlpha)
+		}
+
+		x1 := float32(float64(i) * columnWidth)
+		x2 := float32(float64(i+1) * columnWidth)
+
+		y1 := float32(graph.bottom - graph.values[i]*height)
+		y2 := float32(graph.bottom - graph.values[i+1]*height)
+
+		renderer.DrawQuad(x1, float32(graph.bottom), x1, y1, x2, y2, x2, float32(graph.bottom))
+	}
+
+	renderer.End()
+}
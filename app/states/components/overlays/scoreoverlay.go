@@ -9,6 +9,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/bmath"
 	camera2 "github.com/wieku/danser-go/app/bmath/camera"
+	"github.com/wieku/danser-go/app/dance"
 	"github.com/wieku/danser-go/app/discord"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/input"
@@ -92,6 +93,7 @@ type ScoreOverlay struct {
 	bgDim *animation.Glider
 
 	hitErrorMeter *play.HitErrorMeter
+	strainGraph   *play.StrainGraph
 
 	skip *sprite.Sprite
 
@@ -101,6 +103,12 @@ type ScoreOverlay struct {
 
 	mods       *sprite.SpriteManager
 	notFirst   bool
+
+	modParams     string
+	modParamsFade *animation.Glider
+
+	practice *dance.Practice
+
 	flashlight *common.Flashlight
 	delta      float64
 
@@ -231,6 +239,10 @@ func NewScoreOverlay(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *ScoreOve
 
 	overlay.hitErrorMeter = play.NewHitErrorMeter(overlay.ScaledWidth, overlay.ScaledHeight, ruleset.GetBeatMap().Diff)
 
+	if settings.Gameplay.StrainGraph.Show {
+		overlay.strainGraph = play.NewStrainGraph(ruleset.GetBeatMap(), ruleset.GetMods(cursor), overlay.ScaledWidth, overlay.ScaledHeight)
+	}
+
 	showAfterSkip := 2000.0
 
 	beatLen := overlay.ruleset.GetBeatMap().Timings.GetPoint(0).BaseBpm
@@ -260,6 +272,7 @@ func NewScoreOverlay(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *ScoreOve
 	overlay.boundaries = common.NewBoundaries()
 
 	overlay.mods = sprite.NewSpriteManager()
+	overlay.modParamsFade = animation.NewGlider(0)
 
 	if overlay.ruleset.GetBeatMap().Diff.Mods.Active(difficulty.Flashlight) {
 		overlay.flashlight = common.NewFlashlight(overlay.ruleset.GetBeatMap())
@@ -336,6 +349,30 @@ func (overlay *ScoreOverlay) hitReceived(_ *graphics.Cursor, time int64, number
 	}
 }
 
+// Rewind clears combo, score counters and hit results after the ruleset has been rewound to the given time
+func (overlay *ScoreOverlay) Rewind(time float64) {
+	overlay.audioTime = time
+
+	overlay.combo = 0
+	overlay.newCombo = 0
+
+	if overlay.flashlight != nil {
+		overlay.flashlight.UpdateCombo(0)
+	}
+
+	overlay.results = play.NewHitResults(overlay.ruleset.GetBeatMap().Diff)
+	overlay.hitErrorMeter = play.NewHitErrorMeter(overlay.ScaledWidth, overlay.ScaledHeight, overlay.ruleset.GetBeatMap().Diff)
+	overlay.hpSections = overlay.hpSections[:0]
+
+	accuracy, mCombo, score, _ := overlay.ruleset.GetResults(overlay.cursor)
+
+	overlay.entry.UpdatePlayer(score, mCombo)
+
+	overlay.scoreGlider.SetTarget(float64(score))
+	overlay.accuracyGlider.SetTarget(accuracy)
+	overlay.ppGlider.SetTarget(overlay.ruleset.GetPP(overlay.cursor))
+}
+
 func (overlay *ScoreOverlay) animate(time float64) {
 	overlay.newComboScale.Reset()
 	overlay.newComboScale.AddEventSEase(time, time+50, 1.28, 1.4, easing.InQuad)
@@ -426,6 +463,7 @@ func (overlay *ScoreOverlay) updateNormal(time float64) {
 	}
 
 	overlay.mods.Update(time)
+	overlay.modParamsFade.Update(time)
 
 	overlay.newComboScale.Update(time)
 	overlay.newComboScaleB.Update(time)
@@ -529,6 +567,11 @@ func (overlay *ScoreOverlay) updateBreaks(time float64) {
 	overlay.breakMode = inBreak
 }
 
+// SetPractice shows section and attempts of practice mode
+func (overlay *ScoreOverlay) SetPractice(practice *dance.Practice) {
+	overlay.practice = practice
+}
+
 func (overlay *ScoreOverlay) SetMusic(music *bass.Track) {
 	overlay.music = music
 }
@@ -571,6 +614,13 @@ func (overlay *ScoreOverlay) DrawHUD(batch *batch.QuadBatch, _ []color2.Color, a
 	batch.ResetTransform()
 	batch.SetColor(1, 1, 1, alpha)
 
+	if overlay.strainGraph != nil {
+		batch.Flush()
+
+		overlay.shapeRenderer.SetCamera(overlay.camera.GetProjectionView())
+		overlay.strainGraph.Draw(overlay.shapeRenderer, overlay.getProgress(), alpha)
+	}
+
 	overlay.entry.Draw(batch, alpha)
 
 	overlay.passContainer.Draw(overlay.audioTime, batch)
@@ -585,12 +635,24 @@ func (overlay *ScoreOverlay) DrawHUD(batch *batch.QuadBatch, _ []color2.Color, a
 
 	if settings.Gameplay.Mods.Show {
 		overlay.mods.Draw(overlay.lastTime, batch)
+
+		if overlay.modParams != "" {
+			scale := settings.Gameplay.Mods.Scale
+
+			batch.SetColor(1, 1, 1, alpha*overlay.modParamsFade.GetValue())
+			overlay.keyFont.DrawOrigin(batch, overlay.ScaledWidth-16*scale, 150+40*scale, bmath.Origin.TopRight, 20*scale, false, overlay.modParams)
+			batch.SetColor(1, 1, 1, alpha)
+		}
 	}
 
 	if settings.Gameplay.ShowWarningArrows {
 		overlay.arrows.Draw(overlay.audioTime, batch)
 	}
 
+	if overlay.practice != nil {
+		overlay.keyFont.DrawOrigin(batch, 8, overlay.ScaledHeight-8, bmath.Origin.BottomLeft, 20, false, overlay.practice.GetStatus())
+	}
+
 	overlay.drawPP(batch, alpha)
 
 	if overlay.panel != nil {
@@ -892,6 +954,18 @@ func (overlay *ScoreOverlay) initMods() {
 
 		overlay.mods.Add(mod)
 	}
+
+	// Parameters of rate and Difficulty Adjust mods are shown below the icons, DA, WU and WD don't have skin textures
+	overlay.modParams = overlay.ruleset.GetBeatMap().Diff.GetParamsString()
+
+	if overlay.modParams != "" {
+		overlay.modParamsFade.AddEvent(overlay.audioTime, overlay.audioTime+400, alpha)
+
+		if overlay.cursor.Name == "" || settings.Gameplay.Mods.HideInReplays {
+			startT := overlay.ruleset.GetBeatMap().HitObjects[0].GetStartTime()
+			overlay.modParamsFade.AddEvent(startT, overlay.audioTime+5000, 0)
+		}
+	}
 }
 
 func (overlay *ScoreOverlay) initArrows() {
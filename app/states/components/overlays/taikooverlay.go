@@ -0,0 +1,177 @@
+package overlays
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/audio"
+	"github.com/wieku/danser-go/app/bmath"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const taikoJudgementTime = 300.0
+
+var taikoJudgementTextures = []string{"taiko-hit0", "taiko-hit100", "taiko-hit300"}
+
+// TaikoOverlay shows score, accuracy, combo, health and last judgement of an osu!taiko play
+type TaikoOverlay struct {
+	ruleset *taiko.TaikoRuleSet
+	cursor  *graphics.Cursor
+
+	scoreFont *font.Font
+	comboFont *font.Font
+	textFont  *font.Font
+
+	judgements []*texture.TextureRegion
+
+	lastResult     taiko.HitResult
+	lastResultTime float64
+	time           float64
+
+	audioDisabled bool
+
+	ScaledWidth  float64
+	ScaledHeight float64
+}
+
+func NewTaikoOverlay(ruleset *taiko.TaikoRuleSet, cursor *graphics.Cursor) *TaikoOverlay {
+	overlay := &TaikoOverlay{
+		ruleset:        ruleset,
+		cursor:         cursor,
+		scoreFont:      skin.GetFont("score"),
+		comboFont:      skin.GetFont("combo"),
+		textFont:       font.GetFont("Exo 2 Bold"),
+		lastResultTime: math.Inf(-1),
+		ScaledHeight:   1080,
+	}
+
+	overlay.ScaledWidth = overlay.ScaledHeight * settings.Graphics.GetAspectRatio()
+
+	for _, name := range taikoJudgementTextures {
+		overlay.judgements = append(overlay.judgements, skin.GetTexture(name))
+	}
+
+	ruleset.SetListener(overlay.hitReceived)
+
+	return overlay
+}
+
+func (overlay *TaikoOverlay) hitReceived(_ *graphics.Cursor, time int64, object *taiko.Object, result taiko.HitResult, bonus bool) {
+	if !bonus {
+		overlay.lastResult = result
+		overlay.lastResultTime = float64(time)
+	}
+
+	if overlay.audioDisabled || result == taiko.Miss {
+		return
+	}
+
+	hitSound := object.HitSound
+
+	switch {
+	case object.Big && bonus && object.IsHit():
+		// Second hand on a big note, finish was already played with the first one
+		return
+	case bonus:
+		hitSound = 0
+	case !object.IsHit():
+		// Drum roll ends and swell completions don't have their own sound
+		return
+	}
+
+	point := overlay.ruleset.GetBeatMap().Timings.GetPoint(float64(time))
+
+	audio.PlaySample(point.SampleSet, 0, hitSound, point.SampleIndex, point.SampleVolume, int64(object.Index), 256)
+}
+
+func (overlay *TaikoOverlay) Update(time float64) {
+	overlay.time = time
+}
+
+func (overlay *TaikoOverlay) DrawBeforeObjects(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *TaikoOverlay) DrawNormal(_ *batch.QuadBatch, _ []color2.Color, _ float64) {}
+
+func (overlay *TaikoOverlay) DrawHUD(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	batch.ResetTransform()
+
+	accuracy, _, score, _ := overlay.ruleset.GetResults(overlay.cursor)
+	combo := overlay.ruleset.GetCombo(overlay.cursor)
+
+	fieldScale := settings.Gameplay.Taiko.Scale
+	fieldCentre := settings.Gameplay.Taiko.PositionY
+	laneTop := fieldCentre - 100*fieldScale
+
+	if settings.Gameplay.HpBar.Show {
+		hpAlpha := alpha * settings.Gameplay.HpBar.Opacity
+		hpWidth := overlay.ScaledWidth * 0.4 * settings.Gameplay.HpBar.Scale
+		hpHeight := 12 * settings.Gameplay.HpBar.Scale
+
+		batch.DrawStObject(vector.NewVec2d(0, laneTop-8), bmath.Origin.BottomLeft, vector.NewVec2d(hpWidth, hpHeight), false, false, 0, color2.NewLA(0, float32(0.6*hpAlpha)), false, graphics.Pixel.GetRegion())
+		batch.DrawStObject(vector.NewVec2d(0, laneTop-8), bmath.Origin.BottomLeft, vector.NewVec2d(hpWidth*overlay.ruleset.GetHP(overlay.cursor), hpHeight), false, false, 0, color2.NewRGBA(1, 0.6, 0.2, float32(hpAlpha)), false, graphics.Pixel.GetRegion())
+	}
+
+	if settings.Gameplay.Score.Show {
+		scoreAlpha := alpha * settings.Gameplay.Score.Opacity
+		scoreSize := overlay.scoreFont.GetSize() * settings.Gameplay.Score.Scale * 0.96
+
+		batch.SetColor(1, 1, 1, scoreAlpha)
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, 0, bmath.Origin.TopRight, scoreSize, true, fmt.Sprintf("%08d", score))
+		overlay.scoreFont.DrawOrigin(batch, overlay.ScaledWidth, scoreSize+4.8, bmath.Origin.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", accuracy))
+	}
+
+	if settings.Gameplay.ComboCounter.Show && combo > 0 {
+		comboSize := overlay.comboFont.GetSize() * settings.Gameplay.ComboCounter.Scale * fieldScale * 0.6
+
+		batch.SetColor(1, 1, 1, alpha*settings.Gameplay.ComboCounter.Opacity)
+		overlay.comboFont.DrawOrigin(batch, 125*fieldScale, fieldCentre, bmath.Origin.Centre, comboSize, false, fmt.Sprintf("%d", combo))
+	}
+
+	overlay.drawJudgement(batch, vector.NewVec2d(360*fieldScale, fieldCentre), alpha)
+
+	batch.SetColor(1, 1, 1, 1)
+	batch.ResetTransform()
+}
+
+func (overlay *TaikoOverlay) drawJudgement(batch *batch.QuadBatch, position vector.Vector2d, alpha float64) {
+	progress := (overlay.time - overlay.lastResultTime) / taikoJudgementTime
+	if progress < 0 || progress > 1 {
+		return
+	}
+
+	jAlpha := alpha * (1 - progress*progress)
+	scale := 0.8 + 0.2*math.Sin(math.Min(progress*4, 1)*math.Pi/2)
+
+	position.Y -= 30 * progress * settings.Gameplay.Taiko.Scale
+
+	if tex := overlay.judgements[overlay.lastResult]; tex != nil {
+		batch.SetColor(1, 1, 1, jAlpha)
+		batch.SetScale(scale, scale)
+		batch.DrawStObject(position, bmath.Origin.Centre, vector.NewVec2d(1, 1), false, false, 0, color2.NewL(1), false, *tex)
+		batch.SetScale(1, 1)
+
+		return
+	}
+
+	batch.SetColor(1, 1, 1, jAlpha)
+	overlay.textFont.DrawOrigin(batch, position.X, position.Y, bmath.Origin.Centre, 40*scale, false, overlay.lastResult.String())
+}
+
+func (overlay *TaikoOverlay) IsBroken(_ *graphics.Cursor) bool {
+	return false
+}
+
+func (overlay *TaikoOverlay) DisableAudioSubmission(b bool) {
+	overlay.audioDisabled = b
+}
+
+func (overlay *TaikoOverlay) ShouldDrawHUDBeforeCursor() bool {
+	return true
+}
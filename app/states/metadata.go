@@ -0,0 +1,147 @@
+package states
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/ffmpeg"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	chapterIntro    = "Intro"
+	chapterGameplay = "Gameplay"
+	chapterBreak    = "Break"
+	chapterKiai     = "Kiai"
+	chapterResults  = "Results"
+)
+
+// GetMetadata returns tags and chapters of the recorded video
+func (player *Player) GetMetadata() *ffmpeg.Metadata {
+	return &ffmpeg.Metadata{
+		Artist:     player.bMap.Artist,
+		Title:      player.bMap.Name,
+		Difficulty: player.bMap.Difficulty,
+		Mapper:     player.bMap.Creator,
+		Player:     player.getPlayerNames(),
+		Mods:       player.bMap.Diff.Mods.String(),
+		Chapters:   player.getChapters(),
+	}
+}
+
+func (player *Player) getPlayerNames() string {
+	var names []string
+
+	if controller, ok := player.controller.(*dance.ReplayController); ok {
+		for i := range controller.GetReplays() {
+			names = append(names, controller.GetPlayerName(i))
+		}
+	} else {
+		for _, cursor := range player.controller.GetCursors() {
+			if cursor.Name != "" {
+				names = append(names, cursor.Name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return "danser"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// getChapters splits the video into intro, gameplay, breaks, kiai sections and results screen
+func (player *Player) getChapters() []ffmpeg.Chapter {
+	start := player.startOffset
+	end := player.MapEnd
+
+	firstObject := player.bMap.HitObjects[0].GetStartTime()
+
+	points := []float64{start, end, firstObject, player.mapEndL}
+
+	for _, p := range player.bMap.Pauses {
+		points = append(points, p.GetStartTime(), p.GetEndTime())
+	}
+
+	for _, p := range player.bMap.Timings.Points {
+		points = append(points, p.Time)
+	}
+
+	sort.Float64s(points)
+
+	var chapters []ffmpeg.Chapter
+
+	for i := 1; i < len(points); i++ {
+		from := math.Max(points[i-1], start)
+		to := math.Min(points[i], end)
+
+		if to <= from {
+			continue
+		}
+
+		title := player.getChapterTitle(from, firstObject)
+
+		if last := len(chapters) - 1; last >= 0 && chapters[last].Title == title {
+			chapters[last].End = to
+			continue
+		}
+
+		chapters = append(chapters, ffmpeg.Chapter{
+			Title: title,
+			Start: from,
+			End:   to,
+		})
+	}
+
+	breaks := 0
+
+	for i := range chapters {
+		if chapters[i].Title == chapterBreak {
+			breaks++
+			chapters[i].Title = fmt.Sprintf("%s %d", chapterBreak, breaks)
+		}
+
+		chapters[i].Start = player.GetVideoTime(chapters[i].Start)
+		chapters[i].End = player.GetVideoTime(chapters[i].End)
+	}
+
+	return chapters
+}
+
+func (player *Player) getChapterTitle(time, firstObject float64) string {
+	if time < firstObject {
+		return chapterIntro
+	}
+
+	if time >= player.mapEndL {
+		if player.resultsScreen {
+			return chapterResults
+		}
+
+		return chapterGameplay
+	}
+
+	for _, p := range player.bMap.Pauses {
+		if time >= p.GetStartTime() && time < p.GetEndTime() {
+			return chapterBreak
+		}
+	}
+
+	kiai := false
+
+	for _, p := range player.bMap.Timings.Points {
+		if p.Time > time {
+			break
+		}
+
+		kiai = p.Kiai
+	}
+
+	if kiai {
+		return chapterKiai
+	}
+
+	return chapterGameplay
+}
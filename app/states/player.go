@@ -2,6 +2,7 @@ package states
 
 import (
 	"fmt"
+	"github.com/faiface/mainthread"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/wieku/danser-go/app/beatmap"
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
@@ -39,12 +40,16 @@ import (
 
 const windowsOffset = 15
 
+// Time between updates when the beatmap is simulated after seeking, controllers still judge every millisecond
+const seekSimulationStep = 1000.0 / 60
+
 type Player struct {
 	font        *font.Font
 	bMap        *beatmap.BeatMap
 	bloomEffect *effects.BloomEffect
 
 	lastTime     int64
+	lastDrawMs   float64
 	lastMusicPos float64
 	progressMsF  float64
 	progressMs   int64
@@ -95,6 +100,7 @@ type Player struct {
 
 	objectsAlpha    *animation.Glider
 	objectContainer *containers.HitObjectContainer
+	playfield       containers.Playfield
 
 	MapEnd      float64
 	RunningTime float64
@@ -103,8 +109,20 @@ type Player struct {
 	lateStart   bool
 	mapEndL     float64
 
+	resultsScreen bool
+
 	ScaledWidth  float64
 	ScaledHeight float64
+
+	practice *dance.Practice
+
+	timeline     *common.Timeline
+	sceneGliders []gliderSnapshot
+}
+
+// gliderSnapshot keeps the initial events of a glider so they can be played again after seeking backwards
+type gliderSnapshot struct {
+	glider, initial *animation.Glider
 }
 
 func NewPlayer(beatMap *beatmap.BeatMap) *Player {
@@ -135,7 +153,8 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 		log.Println(err)
 	}
 
-	if (settings.START > 0.01 || !math.IsInf(settings.END, 1)) && (settings.PLAY || !settings.KNOCKOUT) {
+	// Practice keeps the whole beatmap, -start/-end only set the initial section
+	if (settings.START > 0.01 || !math.IsInf(settings.END, 1)) && (settings.PLAY || !settings.KNOCKOUT) && !settings.PRACTICE {
 		scrub := math.Max(0, settings.START*1000)
 		end := settings.END * 1000
 
@@ -174,7 +193,7 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 	}
 
 	player.background = common.NewBackground()
-	player.background.SetBeatmap(beatMap, settings.Playfield.Background.LoadStoryboards)
+	player.background.SetBeatmap(beatMap, settings.Playfield.Background.LoadStoryboards && !settings.Recording.IsTransparent())
 
 	player.mainCamera = camera2.NewCamera()
 	player.mainCamera.SetOsuViewport(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()), settings.Playfield.Scale, settings.Playfield.OsuShift)
@@ -202,12 +221,44 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 	player.bMap.Reset()
 
-	if settings.PLAY {
+	if beatMap.Mode == 3 {
+		controller := dance.NewManiaController()
+		player.controller = controller
+
+		player.controller.SetBeatMap(player.bMap)
+		player.controller.InitCursors()
+
+		player.overlay = overlays.NewManiaOverlay(controller.GetRuleset(), controller.GetCursors()[0])
+		player.playfield = containers.NewManiaPlayfield(controller.GetRuleset(), controller.GetCursors()[0], player.ScaledWidth, player.ScaledHeight)
+	} else if beatMap.Mode == 1 {
+		controller := dance.NewTaikoController()
+		player.controller = controller
+
+		player.controller.SetBeatMap(player.bMap)
+		player.controller.InitCursors()
+
+		player.overlay = overlays.NewTaikoOverlay(controller.GetRuleset(), controller.GetCursors()[0])
+		player.playfield = containers.NewTaikoPlayfield(controller.GetRuleset(), controller.GetCursors()[0], player.ScaledWidth, player.ScaledHeight)
+	} else if beatMap.Mode == 2 {
+		controller := dance.NewCatchController()
+		player.controller = controller
+
+		player.controller.SetBeatMap(player.bMap)
+		player.controller.InitCursors()
+
+		player.overlay = overlays.NewCatchOverlay(controller.GetRuleset(), controller.GetCursors()[0])
+		player.playfield = containers.NewCatchPlayfield(controller.GetRuleset(), controller.GetCursors()[0], player.ScaledWidth, player.ScaledHeight)
+	} else if settings.PLAY {
 		player.controller = dance.NewPlayerController()
 
 		player.controller.SetBeatMap(player.bMap)
 		player.controller.InitCursors()
 		player.overlay = overlays.NewScoreOverlay(player.controller.(*dance.PlayerController).GetRuleset(), player.controller.GetCursors()[0])
+
+		player.practice = player.controller.(*dance.PlayerController).GetPractice()
+		if player.practice != nil {
+			player.overlay.(*overlays.ScoreOverlay).SetPractice(player.practice)
+		}
 	} else if settings.KNOCKOUT {
 		controller := dance.NewReplayController()
 		player.controller = controller
@@ -228,7 +279,9 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 	player.lastTime = -1
 
-	player.objectContainer = containers.NewHitObjectContainer(beatMap)
+	if player.playfield == nil {
+		player.objectContainer = containers.NewHitObjectContainer(beatMap)
+	}
 
 	log.Println("Audio track:", beatMap.Audio)
 
@@ -237,7 +290,7 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 	player.fadeIn = 0.0
 
 	player.volumeGlider = animation.NewGlider(1)
-	player.speedGlider = animation.NewGlider(settings.SPEED)
+	player.speedGlider = animation.NewGlider(beatMap.Diff.Speed)
 	player.pitchGlider = animation.NewGlider(settings.PITCH)
 
 	player.hudGlider = animation.NewGlider(0)
@@ -275,6 +328,11 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 		beatmapEnd = math.Min(end, beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime()) + float64(beatMap.Diff.Hit50)
 	}
 
+	if player.practice != nil {
+		// The section is looped until the window is closed
+		beatmapEnd = math.Inf(1)
+	}
+
 	startOffset := 0.0
 
 	if settings.SKIP || settings.START > 0.01 {
@@ -371,6 +429,8 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 	player.MapEnd = beatmapEnd + fadeOut
 
 	if _, ok := player.overlay.(*overlays.ScoreOverlay); ok && settings.Gameplay.ShowResultsScreen {
+		player.resultsScreen = true
+
 		player.speedGlider.AddEvent(beatmapEnd+fadeOut, beatmapEnd+fadeOut, 1)
 		player.pitchGlider.AddEvent(beatmapEnd+fadeOut, beatmapEnd+fadeOut, 1)
 
@@ -398,26 +458,13 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 	player.RunningTime = player.MapEnd - startOffset
 
-	for _, p := range beatMap.Pauses {
-		startTime := p.GetStartTime()
-		endTime := p.GetEndTime()
+	player.addBreakEvents(player.startPoint)
 
-		if endTime-startTime < 1000*settings.SPEED || endTime < player.startPoint || startTime > player.MapEnd {
-			continue
-		}
+	if _, ok := player.controller.(dance.Resettable); ok && !settings.RECORD && !settings.PLAY && !settings.SAVEREPLAY {
+		player.timeline = common.NewTimeline(player.startOffset, player.mapEndL)
+		input.RegisterListener(player.timeline.KeyEvent)
 
-		player.dimGlider.AddEvent(startTime, startTime+1000*settings.SPEED, 1.0-settings.Playfield.Background.Dim.Breaks)
-		player.blurGlider.AddEvent(startTime, startTime+1000*settings.SPEED, settings.Playfield.Background.Blur.Values.Breaks)
-		player.fxGlider.AddEvent(startTime, startTime+1000*settings.SPEED, 1.0-settings.Playfield.Logo.Dim.Breaks)
-
-		if !settings.Cursor.ShowCursorsOnBreaks {
-			player.cursorGlider.AddEvent(startTime, startTime+100*settings.SPEED, 0.0)
-		}
-
-		player.dimGlider.AddEvent(endTime, endTime+1000*settings.SPEED, 1.0-settings.Playfield.Background.Dim.Normal)
-		player.blurGlider.AddEvent(endTime, endTime+1000*settings.SPEED, settings.Playfield.Background.Blur.Values.Normal)
-		player.fxGlider.AddEvent(endTime, endTime+1000*settings.SPEED, 1.0-settings.Playfield.Logo.Dim.Normal)
-		player.cursorGlider.AddEvent(endTime, endTime+1000*settings.SPEED, 1.0)
+		player.snapshotGliders()
 	}
 
 	player.background.SetTrack(player.musicPlayer)
@@ -468,21 +515,26 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 			player.profilerU.PutSample(delta)
 
+			timeDelta := delta
+			if player.timeline != nil && player.timeline.IsPaused() {
+				timeDelta = 0
+			}
+
 			if player.musicPlayer.GetState() == bass.MUSIC_STOPPED {
-				player.progressMsF += delta
+				player.progressMsF += timeDelta
 			} else {
 				platformOffset := 0.0
 				if runtime.GOOS == "windows" {
 					platformOffset = windowsOffset
 				}
 
-				musicPos := player.musicPlayer.GetPosition()*1000 + (platformOffset+float64(settings.Audio.Offset))*settings.SPEED
+				musicPos := player.musicPlayer.GetPosition()*1000 + (platformOffset+float64(settings.Audio.Offset))*player.musicPlayer.GetTempo()
 
 				if musicPos != player.lastMusicPos {
 					player.progressMsF = musicPos
 					player.lastMusicPos = musicPos
 				} else {
-					player.progressMsF += delta * settings.SPEED
+					player.progressMsF += timeDelta * player.musicPlayer.GetTempo()
 				}
 			}
 
@@ -525,6 +577,215 @@ func (player *Player) Update(delta float64) bool {
 	return false
 }
 
+func (player *Player) addBreakEvents(from float64) {
+	for _, p := range player.bMap.Pauses {
+		startTime := p.GetStartTime()
+		endTime := p.GetEndTime()
+
+		startSpeed := player.bMap.Diff.GetSpeedAt(startTime)
+		endSpeed := player.bMap.Diff.GetSpeedAt(endTime)
+
+		if endTime-startTime < 1000*startSpeed || endTime < from || startTime > player.MapEnd {
+			continue
+		}
+
+		player.dimGlider.AddEvent(startTime, startTime+1000*startSpeed, 1.0-settings.Playfield.Background.Dim.Breaks)
+		player.blurGlider.AddEvent(startTime, startTime+1000*startSpeed, settings.Playfield.Background.Blur.Values.Breaks)
+		player.fxGlider.AddEvent(startTime, startTime+1000*startSpeed, 1.0-settings.Playfield.Logo.Dim.Breaks)
+
+		if !settings.Cursor.ShowCursorsOnBreaks {
+			player.cursorGlider.AddEvent(startTime, startTime+100*startSpeed, 0.0)
+		}
+
+		player.dimGlider.AddEvent(endTime, endTime+1000*endSpeed, 1.0-settings.Playfield.Background.Dim.Normal)
+		player.blurGlider.AddEvent(endTime, endTime+1000*endSpeed, settings.Playfield.Background.Blur.Values.Normal)
+		player.fxGlider.AddEvent(endTime, endTime+1000*endSpeed, 1.0-settings.Playfield.Logo.Dim.Normal)
+		player.cursorGlider.AddEvent(endTime, endTime+1000*endSpeed, 1.0)
+	}
+}
+
+// rewind plays the beatmap again from the given time without reloading it, used by practice mode
+func (player *Player) rewind(time float64) {
+	bass.StopLoops()
+
+	if player.musicPlayer.GetState() != bass.MUSIC_PLAYING {
+		player.musicPlayer.Play()
+	}
+
+	player.musicPlayer.SetPosition(time / 1000)
+
+	player.progressMsF = time
+
+	// Slider bodies are recreated and objects are drawn on the main thread
+	mainthread.Call(func() {
+		player.bMap.Rewind(time)
+		player.objectContainer.Rewind(time)
+
+		player.controller.(*dance.PlayerController).Rewind(time)
+		player.overlay.(*overlays.ScoreOverlay).Rewind(time)
+	})
+
+	player.dimGlider.Reset()
+	player.dimGlider.SetValue(1.0 - settings.Playfield.Background.Dim.Normal)
+
+	player.blurGlider.Reset()
+	player.blurGlider.SetValue(settings.Playfield.Background.Blur.Values.Normal)
+
+	player.fxGlider.Reset()
+	player.fxGlider.SetValue(1.0 - settings.Playfield.Logo.Dim.Normal)
+
+	player.cursorGlider.Reset()
+	player.cursorGlider.SetValue(1.0)
+
+	player.addBreakEvents(time)
+}
+
+func (player *Player) startMusic(position float64) {
+	player.musicPlayer.Play()
+
+	if ov, ok := player.overlay.(*overlays.ScoreOverlay); ok {
+		ov.SetMusic(player.musicPlayer)
+	}
+
+	player.musicPlayer.SetPosition(position / 1000)
+
+	discord.SetDuration(int64(player.bMap.Diff.ToRealTime(player.musicPlayer.GetLength()*1000) - player.bMap.Diff.ToRealTime(player.musicPlayer.GetPosition()*1000)))
+
+	if player.overlay == nil {
+		discord.UpdateDance(settings.TAG, settings.DIVIDES)
+	}
+
+	player.start = true
+}
+
+func (player *Player) snapshotGliders() {
+	gliders := []*animation.Glider{player.volumeGlider, player.speedGlider, player.pitchGlider, player.hudGlider, player.dimGlider, player.blurGlider, player.fxGlider, player.cursorGlider, player.epiGlider, player.objectsAlpha}
+
+	for _, g := range gliders {
+		player.sceneGliders = append(player.sceneGliders, gliderSnapshot{g, g.Clone()})
+	}
+}
+
+func (player *Player) updateTimeline(delta float64) {
+	player.timeline.Update(player.progressMsF, delta, player.ScaledWidth, player.ScaledHeight)
+
+	if time, ok := player.timeline.PollSeek(); ok {
+		player.seek(time)
+	}
+
+	if paused := player.timeline.IsPaused(); paused && player.musicPlayer.GetState() == bass.MUSIC_PLAYING {
+		player.musicPlayer.Pause()
+		bass.StopLoops()
+	} else if !paused && player.musicPlayer.GetState() == bass.MUSIC_PAUSED {
+		player.musicPlayer.Resume()
+	}
+}
+
+// seek moves playback to the given time, going backwards plays the beatmap again from the beginning without sounds
+func (player *Player) seek(time float64) {
+	bass.StopLoops()
+
+	// Cursors and objects aren't updated before startPointE when playing normally
+	from := math.Max(player.progressMsF, player.startPointE)
+
+	if time < player.progressMsF {
+		// Slider bodies are recreated on the main thread
+		mainthread.Call(func() {
+			from = player.resetPlayback()
+		})
+	}
+
+	// Simulation stays on this thread so the window keeps drawing while it's running
+	player.simulate(from, time)
+
+	player.progressMsF = time
+
+	if time < player.startPoint {
+		player.musicPlayer.Stop()
+		player.start = false
+	} else {
+		if !player.start {
+			player.startMusic(time)
+		}
+
+		player.musicPlayer.SetPosition(time / 1000)
+
+		if player.timeline.IsPaused() {
+			player.musicPlayer.Pause()
+		}
+	}
+}
+
+// resetPlayback brings objects, cursors, scores and effects back to the state before the beatmap started, it returns the time simulation has to start from
+func (player *Player) resetPlayback() float64 {
+	start := math.Inf(-1)
+
+	player.bMap.Rewind(start)
+	player.objectContainer.Rewind(start)
+
+	player.controller.(dance.Resettable).Reset()
+
+	switch overlay := player.overlay.(type) {
+	case *overlays.ScoreOverlay:
+		overlay.Rewind(start)
+	case *overlays.KnockoutOverlay:
+		overlay.Rewind(start)
+	}
+
+	if storyboard := player.background.GetStoryboard(); storyboard != nil {
+		storyboard.Rewind()
+	}
+
+	for _, s := range player.sceneGliders {
+		*s.glider = *s.initial.Clone()
+	}
+
+	// Same as simulation done for -start
+	return math.Min(-1000, player.startPointE)
+}
+
+// simulate updates the beatmap and cursors between the given times without playing any sounds, playback time follows the simulation
+func (player *Player) simulate(from, to float64) {
+	if from >= to {
+		return
+	}
+
+	audioDisabled := make([]bool, len(player.bMap.HitObjects))
+
+	for i, o := range player.bMap.HitObjects {
+		audioDisabled[i] = o.IsAudioSubmissionDisabled()
+		o.DisableAudioSubmission(true)
+	}
+
+	if player.overlay != nil {
+		player.overlay.DisableAudioSubmission(true)
+	}
+
+	_, generic := player.controller.(*dance.GenericController)
+
+	for t := from; t < to; t += seekSimulationStep {
+		player.progressMsF = t
+
+		if generic {
+			player.bMap.Update(t)
+		}
+
+		player.controller.Update(t, seekSimulationStep)
+
+		if player.overlay != nil {
+			player.overlay.Update(t)
+		}
+	}
+
+	if player.overlay != nil {
+		player.overlay.DisableAudioSubmission(false)
+	}
+
+	for i, o := range player.bMap.HitObjects {
+		o.DisableAudioSubmission(audioDisabled[i])
+	}
+}
+
 func (player *Player) GetTime() float64 {
 	return player.progressMsF
 }
@@ -533,29 +794,60 @@ func (player *Player) GetTimeOffset() float64 {
 	return player.progressMsF - player.startOffset
 }
 
-func (player *Player) updateMain(delta float64) {
-	if player.progressMsF >= player.startPoint && !player.start {
-		player.musicPlayer.Play()
+// GetVideoTime converts beatmap time to the time passed since the player started, rate changing mods are taken into account
+func (player *Player) GetVideoTime(time float64) float64 {
+	time = math.Max(time, player.startOffset)
+
+	// Music is not playing before startPoint so time passes at normal rate
+	if time <= player.startPoint {
+		return time - player.startOffset
+	}
+
+	diff := player.bMap.Diff
 
-		if ov, ok := player.overlay.(*overlays.ScoreOverlay); ok {
-			ov.SetMusic(player.musicPlayer)
+	videoTime := player.startPoint - player.startOffset + diff.ToRealTime(math.Min(time, player.mapEndL)) - diff.ToRealTime(player.startPoint)
+
+	if time > player.mapEndL {
+		endSpeed := diff.Speed
+		if player.resultsScreen {
+			endSpeed = 1
 		}
 
-		player.musicPlayer.SetPosition(player.startPoint / 1000)
+		videoTime += (time - player.mapEndL) / endSpeed
+	}
 
-		discord.SetDuration(int64((player.musicPlayer.GetLength() - player.musicPlayer.GetPosition()) * 1000 / settings.SPEED))
+	return videoTime
+}
 
-		if player.overlay == nil {
-			discord.UpdateDance(settings.TAG, settings.DIVIDES)
+func (player *Player) updateMain(delta float64) {
+	if player.practice != nil {
+		if time, ok := player.practice.PollRewind(); ok {
+			player.rewind(time)
 		}
+	}
 
-		player.start = true
+	if player.timeline != nil {
+		player.updateTimeline(delta)
+	}
+
+	if player.progressMsF >= player.startPoint && !player.start {
+		player.startMusic(player.startPoint)
 	}
 
 	player.speedGlider.Update(player.progressMsF)
 	player.pitchGlider.Update(player.progressMsF)
 
-	player.musicPlayer.SetTempo(player.speedGlider.GetValue())
+	speed := player.speedGlider.GetValue()
+	if player.progressMsF < player.mapEndL {
+		// Wind Up/Wind Down change the rate until the beatmap ends
+		speed = player.bMap.Diff.GetSpeedAt(player.progressMsF)
+	}
+
+	if player.practice != nil {
+		speed *= player.practice.GetRate()
+	}
+
+	player.musicPlayer.SetTempo(speed)
 	player.musicPlayer.SetPitch(player.pitchGlider.GetValue())
 
 	if player.progressMsF >= player.startPointE {
@@ -563,7 +855,11 @@ func (player *Player) updateMain(delta float64) {
 			player.bMap.Update(player.progressMsF)
 		}
 
-		player.objectContainer.Update(player.progressMsF)
+		if player.playfield != nil {
+			player.playfield.Update(player.progressMsF)
+		} else {
+			player.objectContainer.Update(player.progressMsF)
+		}
 	}
 
 	if player.progressMsF >= player.startPointE || settings.PLAY {
@@ -655,6 +951,13 @@ func (player *Player) Draw(float64) {
 	player.profiler.PutSample(timMs)
 	player.lastTime = tim
 
+	colorsDelta := timMs
+	if settings.RECORD {
+		// Recorded frames can't depend on how long they took to render, segments recorded in parallel wouldn't match otherwise
+		colorsDelta = bass.GlobalTimeMs - player.lastDrawMs
+		player.lastDrawMs = bass.GlobalTimeMs
+	}
+
 	cameras := player.mainCamera.GenRotated(settings.DIVIDES, -2*math.Pi/float64(settings.DIVIDES))
 
 	bgAlpha := player.dimGlider.GetValue()
@@ -662,10 +965,12 @@ func (player *Player) Draw(float64) {
 		bgAlpha = bmath.ClampF64(bgAlpha*player.Scl, 0, 1)
 	}
 
-	player.background.Draw(player.progressMsF, player.batch, player.blurGlider.GetValue(), bgAlpha, player.bgCamera.GetProjectionView())
+	if !settings.Recording.IsTransparent() {
+		player.background.Draw(player.progressMsF, player.batch, player.blurGlider.GetValue(), bgAlpha, player.bgCamera.GetProjectionView())
+	}
 
 	if player.start {
-		settings.Cursor.Colors.Update(timMs)
+		settings.Cursor.Colors.Update(colorsDelta)
 	}
 
 	cursorColors := settings.Cursor.GetColors(settings.DIVIDES, len(player.controller.GetCursors()), player.Scl, player.cursorGlider.GetValue())
@@ -710,7 +1015,12 @@ func (player *Player) Draw(float64) {
 		player.bloomEffect.Begin()
 	}
 
-	player.objectContainer.Draw(player.batch, cameras, player.progressMsF, float32(player.Scl), float32(player.objectsAlpha.GetValue()))
+	if player.playfield != nil {
+		player.batch.SetCamera(player.uiCamera.GetProjectionView())
+		player.playfield.Draw(player.batch, player.progressMsF, player.objectsAlpha.GetValue())
+	} else {
+		player.objectContainer.Draw(player.batch, cameras, player.progressMsF, float32(player.Scl), float32(player.objectsAlpha.GetValue()))
+	}
 
 	if player.overlay != nil {
 		player.batch.Begin()
@@ -723,13 +1033,15 @@ func (player *Player) Draw(float64) {
 		player.batch.End()
 	}
 
-	player.background.DrawOverlay(player.progressMsF, player.batch, bgAlpha, player.bgCamera.GetProjectionView())
+	if !settings.Recording.IsTransparent() {
+		player.background.DrawOverlay(player.progressMsF, player.batch, bgAlpha, player.bgCamera.GetProjectionView())
+	}
 
 	if player.overlay != nil && player.overlay.ShouldDrawHUDBeforeCursor() {
 		player.drawHUD(cursorColors)
 	}
 
-	if settings.Playfield.DrawCursors {
+	if settings.Playfield.DrawCursors && player.playfield == nil {
 		for _, g := range player.controller.GetCursors() {
 			g.UpdateRenderer()
 		}
@@ -773,6 +1085,10 @@ func (player *Player) Draw(float64) {
 		player.bloomEffect.EndAndRender()
 	}
 
+	if player.timeline != nil {
+		player.drawTimeline()
+	}
+
 	player.drawDebug()
 }
 
@@ -824,6 +1140,15 @@ func (player *Player) drawHUD(cursorColors []color2.Color) {
 	player.batch.End()
 }
 
+func (player *Player) drawTimeline() {
+	player.batch.Begin()
+	player.batch.SetCamera(player.uiCamera.GetProjectionView())
+
+	player.timeline.Draw(player.batch, player.ScaledWidth, player.ScaledHeight)
+
+	player.batch.End()
+}
+
 func (player *Player) drawDebug() {
 	if settings.DEBUG || settings.Graphics.ShowFPS {
 		padDown := 4.0
@@ -0,0 +1,86 @@
+package states
+
+import (
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/database"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"path/filepath"
+)
+
+// SaveResults exports current session as an osu! replay if it was requested
+// and stores results of finished -play and -replay sessions in the local score database
+func (player *Player) SaveResults() {
+	replayPath := ""
+
+	if saver, ok := player.controller.(dance.ReplaySaver); ok && settings.SAVEREPLAY {
+		replayPath = saver.SaveReplay()
+	}
+
+	var ruleset *osu.OsuRuleSet
+	var cursors []*graphics.Cursor
+	var names []string
+
+	switch controller := player.controller.(type) {
+	case *dance.PlayerController:
+		ruleset = controller.GetRuleset()
+		cursors = controller.GetCursors()
+		names = []string{cursors[0].Name}
+	case *dance.ReplayController:
+		if settings.REPLAY == "" {
+			return
+		}
+
+		ruleset = controller.GetRuleset()
+		cursors = controller.GetCursors()
+
+		for i := range cursors {
+			names = append(names, controller.GetPlayerName(i))
+		}
+
+		replayPath, _ = filepath.Abs(settings.REPLAY)
+	default:
+		return
+	}
+
+	if !ruleset.IsEnded() {
+		return
+	}
+
+	if err := database.Init(); err != nil {
+		log.Println("Failed to initialize database:", err)
+		return
+	}
+
+	defer database.Close()
+
+	for i, cursor := range cursors {
+		accuracy, maxCombo, score, grade := ruleset.GetResults(cursor)
+		n300, n100, n50, nMiss, nGeki, nKatu := ruleset.GetHits(cursor)
+
+		err := database.InsertScore(&database.Score{
+			MD5:        player.bMap.MD5,
+			Name:       names[i],
+			Mods:       ruleset.GetMods(cursor).String(),
+			Score:      score,
+			Accuracy:   accuracy,
+			MaxCombo:   maxCombo,
+			Count300:   n300,
+			Count100:   n100,
+			Count50:    n50,
+			CountMiss:  nMiss,
+			CountGeki:  nGeki,
+			CountKatu:  nKatu,
+			Grade:      osu.GradesText[grade],
+			PP:         ruleset.GetPP(cursor),
+			Time:       cursor.ScoreTime,
+			ReplayPath: replayPath,
+		})
+
+		if err != nil {
+			log.Println("Failed to save the score:", err)
+		}
+	}
+}
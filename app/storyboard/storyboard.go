@@ -12,6 +12,7 @@ import (
 	"github.com/wieku/danser-go/framework/graphics/sprite"
 	"github.com/wieku/danser-go/framework/graphics/texture"
 	video2 "github.com/wieku/danser-go/framework/graphics/video"
+	"github.com/wieku/danser-go/framework/math/animation"
 	"github.com/wieku/danser-go/framework/math/vector"
 	"github.com/wieku/danser-go/framework/qpc"
 	"github.com/wieku/danser-go/framework/util"
@@ -39,6 +40,16 @@ type Storyboard struct {
 	counter     *frame.Counter
 	numSprites  int
 	pathCache   *utils.FileMap
+
+	sprites       []storyboardSprite
+	rewindPending bool
+}
+
+// storyboardSprite keeps sprite's layer and commands so it can be shown again after a rewind
+type storyboardSprite struct {
+	sprite     sprite.ISprite
+	layer      *sprite.SpriteManager
+	transforms []*animation.Transformation
 }
 
 func getSection(line string) string {
@@ -141,7 +152,7 @@ func NewStoryboard(beatMap *beatmap.BeatMap) *Storyboard {
 					video.SetEndTime(math.MaxFloat64)
 					video.ShowForever(false)
 
-					storyboard.background.Add(video)
+					storyboard.addSprite(video, storyboard.background, nil)
 
 					hasVideo = true
 				} else if settings.Playfield.Background.LoadStoryboards {
@@ -252,19 +263,25 @@ func (storyboard *Storyboard) loadSprite(path, currentSprite string, commands []
 
 		switch spl[1] {
 		case "0", "Background":
-			storyboard.background.Add(sbSprite)
+			storyboard.addSprite(sbSprite, storyboard.background, transforms)
 		case "2", "Pass":
-			storyboard.pass.Add(sbSprite)
+			storyboard.addSprite(sbSprite, storyboard.pass, transforms)
 		case "3", "Foreground":
-			storyboard.foreground.Add(sbSprite)
+			storyboard.addSprite(sbSprite, storyboard.foreground, transforms)
 		case "4", "Overlay":
-			storyboard.overlay.Add(sbSprite)
+			storyboard.addSprite(sbSprite, storyboard.overlay, transforms)
 		}
 
 		storyboard.numSprites++
 	}
 }
 
+func (storyboard *Storyboard) addSprite(sbSprite sprite.ISprite, layer *sprite.SpriteManager, transforms []*animation.Transformation) {
+	layer.Add(sbSprite)
+
+	storyboard.sprites = append(storyboard.sprites, storyboardSprite{sbSprite, layer, transforms})
+}
+
 func (storyboard *Storyboard) getTexture(image string) *texture.TextureRegion {
 	var texture1 *texture.TextureRegion
 
@@ -351,13 +368,41 @@ func (storyboard *Storyboard) SetFPS(i int) {
 	storyboard.limiter.FPS = i
 }
 
+// Rewind makes the storyboard play again from the beginning, sprites are restored on the next Update
+func (storyboard *Storyboard) Rewind() {
+	storyboard.rewindPending = true
+}
+
 func (storyboard *Storyboard) Update(time float64) {
+	if storyboard.rewindPending {
+		storyboard.rewindPending = false
+		storyboard.restoreSprites()
+	}
+
 	storyboard.background.Update(time)
 	storyboard.pass.Update(time)
 	storyboard.foreground.Update(time)
 	storyboard.overlay.Update(time)
 }
 
+// restoreSprites adds all sprites to their layers again with their original transformations
+func (storyboard *Storyboard) restoreSprites() {
+	storyboard.background.Clear()
+	storyboard.pass.Clear()
+	storyboard.foreground.Clear()
+	storyboard.overlay.Clear()
+
+	for _, s := range storyboard.sprites {
+		if sbSprite, ok := s.sprite.(*sprite.Sprite); ok && s.transforms != nil {
+			sbSprite.ClearTransformations()
+			sbSprite.AddTransforms(s.transforms)
+			sbSprite.ResetValuesToTransforms()
+		}
+
+		s.layer.Add(s.sprite)
+	}
+}
+
 func (storyboard *Storyboard) Draw(time float64, batch *batch.QuadBatch) {
 	batch.SetTranslation(vector.NewVec2d(-64, -48))
 	storyboard.background.Draw(time, batch)
@@ -30,6 +30,12 @@ var Offscreen = false
 
 var mixStream C.HSTREAM
 
+// Position of the mixer in milliseconds when music events are processed outside of mixing
+var mixStartMs float64
+
+var streamEvents int
+var streamBytes C.QWORD
+
 type trackEvent struct {
 	channel  C.DWORD
 	time     float64
@@ -89,10 +95,76 @@ func SaveToFile(file string) {
 	}
 
 	C.BASS_Encode_Stop(mixStream) // close the WAV writer
+	C.BASS_StreamFree(mixStream)
+
+	// Start from scratch in case another recording is made
+	trackEvents = make([]trackEvent, 0)
+	GlobalTimeMs = 0
 
 	log.Println("Encoding finished!")
 }
 
+// StartStream creates a mixing stream that is read along with recording by ReadStream, instead of SaveToFile after it
+func StartStream() {
+	mixStream = C.BASS_Mixer_StreamCreate(48000, 2, C.BASS_STREAM_DECODE|C.BASS_MIXER_NONSTOP|C.BASS_SAMPLE_FLOAT)
+
+	streamEvents = 0
+	streamBytes = 0
+
+	log.Println("Audio mixing stream created")
+}
+
+// ReadStream mixes audio up to the given time and returns it as 48kHz stereo float32 PCM.
+// Events have to be added before their time is read, so it should be called with GlobalTimeMs.
+func ReadStream(timeMs float64) []byte {
+	for ; streamEvents < len(trackEvents); streamEvents++ {
+		e := trackEvents[streamEvents]
+
+		//Music is added right away with a delay, same as in SaveToFile
+		if e.play && e.channel != 0 {
+			mixStartMs = float64(C.BASS_ChannelBytes2Seconds(mixStream, streamBytes)) * 1000
+			processEvent(streamEvents)
+			mixStartMs = 0
+
+			continue
+		}
+
+		pos := C.BASS_ChannelSeconds2Bytes(mixStream, C.double(e.time/1000))
+		C.SetSync(mixStream, pos, C.int(streamEvents))
+	}
+
+	target := C.BASS_ChannelSeconds2Bytes(mixStream, C.double(timeMs/1000))
+	if target <= streamBytes {
+		return nil
+	}
+
+	data := make([]byte, int(target-streamBytes))
+
+	for read := 0; read < len(data); {
+		ret := int32(C.BASS_ChannelGetData(mixStream, unsafe.Pointer(&data[read]), C.DWORD(len(data)-read)))
+		if ret <= 0 {
+			data = data[:read]
+			break
+		}
+
+		read += int(ret)
+	}
+
+	streamBytes += C.QWORD(len(data))
+
+	return data
+}
+
+// StopStream frees the mixing stream created by StartStream
+func StopStream() {
+	C.BASS_StreamFree(mixStream)
+
+	trackEvents = make([]trackEvent, 0)
+	GlobalTimeMs = 0
+
+	log.Println("Audio mixing stream closed")
+}
+
 //export goCallback
 func goCallback(i C.int) {
 	eventIndex := int(i)
@@ -128,7 +200,7 @@ func processEvent(eventIndex int) {
 		if ret != 0 { //add samples to the queue
 			C.BASS_Mixer_StreamAddChannel(mixStream, ret, C.BASS_STREAM_AUTOFREE|C.BASS_MIXER_CHAN_NORAMPIN)
 		} else { //push main music to the queue
-			pos := C.BASS_ChannelSeconds2Bytes(mixStream, C.double(event.time/1000))
+			pos := C.BASS_ChannelSeconds2Bytes(mixStream, C.double((event.time-mixStartMs)/1000))
 			C.BASS_Mixer_StreamAddChannelEx(mixStream, event.channel, C.BASS_STREAM_AUTOFREE|C.BASS_MIXER_CHAN_NORAMPIN, pos, C.QWORD(0))
 		}
 	}
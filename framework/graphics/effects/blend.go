@@ -71,7 +71,8 @@ func NewBlend(width, height, frames int, weights []float32) *Blend {
 		effect.blendShader.SetUniformArr("weights", i, v/sum)
 	}
 
-	effect.multiTexture = texture.NewTextureMultiLayerFormat(width, height, texture.RGB, 0, frames)
+	// Frames are blended with alpha so transparent recordings keep it, opaque frames stay opaque as weights sum up to 1
+	effect.multiTexture = texture.NewTextureMultiLayerFormat(width, height, texture.RGBA, 0, frames)
 
 	for i := 0; i < frames; i++ {
 		effect.fbos = append(effect.fbos, buffer.NewFrameLayer(effect.multiTexture, i))
@@ -83,7 +84,7 @@ func NewBlend(width, height, frames int, weights []float32) *Blend {
 func (effect *Blend) Begin() {
 	effect.head = (effect.head + 1) % effect.layers
 	effect.fbos[effect.head].Bind()
-	effect.fbos[effect.head].ClearColor(0, 0, 0, 1)
+	effect.fbos[effect.head].ClearColor(0, 0, 0, 0)
 	viewport.Push(effect.width, effect.height)
 }
 
@@ -0,0 +1,93 @@
+package effects
+
+import (
+	"github.com/wieku/danser-go/framework/assets"
+	"github.com/wieku/danser-go/framework/graphics/attribute"
+	"github.com/wieku/danser-go/framework/graphics/blend"
+	"github.com/wieku/danser-go/framework/graphics/buffer"
+	"github.com/wieku/danser-go/framework/graphics/shader"
+)
+
+// CoverageEffect fixes alpha of premultiplied frames drawn over transparent background.
+// Additive draws (glows, bloom) leave alpha untouched so it's raised to the brightest colour channel.
+type CoverageEffect struct {
+	shader *shader.RShader
+	fbo    *buffer.Framebuffer
+	vao    *buffer.VertexArrayObject
+}
+
+func NewCoverageEffect(width, height int) *CoverageEffect {
+	effect := new(CoverageEffect)
+
+	vert, err := assets.GetString("assets/shaders/fbopass.vsh")
+	if err != nil {
+		panic(err)
+	}
+
+	frag, err := assets.GetString("assets/shaders/coverage.fsh")
+	if err != nil {
+		panic(err)
+	}
+
+	effect.shader = shader.NewRShader(shader.NewSource(vert, shader.Vertex), shader.NewSource(frag, shader.Fragment))
+
+	effect.vao = buffer.NewVertexArrayObject()
+
+	effect.vao.AddVBO("default", 6, 0, attribute.Format{
+		{Name: "in_position", Type: attribute.Vec3},
+		{Name: "in_tex_coord", Type: attribute.Vec2},
+	})
+
+	effect.vao.SetData("default", 0, []float32{
+		-1, -1, 0, 0, 0,
+		1, -1, 0, 1, 0,
+		-1, 1, 0, 0, 1,
+		1, -1, 0, 1, 0,
+		1, 1, 0, 1, 1,
+		-1, 1, 0, 0, 1,
+	})
+
+	effect.vao.Attach(effect.shader)
+
+	effect.fbo = buffer.NewFrame(width, height, true, false)
+
+	return effect
+}
+
+func (effect *CoverageEffect) GetWidth() int {
+	return effect.fbo.GetWidth()
+}
+
+func (effect *CoverageEffect) GetHeight() int {
+	return effect.fbo.GetHeight()
+}
+
+func (effect *CoverageEffect) Begin() {
+	effect.fbo.Bind()
+	effect.fbo.ClearColor(0, 0, 0, 0)
+}
+
+// EndAndRender replaces content of the previously bound framebuffer with the fixed frame
+func (effect *CoverageEffect) EndAndRender() {
+	effect.fbo.Unbind()
+
+	blend.Push()
+	blend.Disable()
+
+	effect.shader.Bind()
+	effect.shader.SetUniform("tex", int32(0))
+
+	effect.fbo.Texture().Bind(0)
+
+	effect.vao.Bind()
+	effect.vao.Draw()
+	effect.vao.Unbind()
+
+	effect.shader.Unbind()
+
+	blend.Pop()
+}
+
+func (effect *CoverageEffect) Dispose() {
+	effect.fbo.Dispose()
+}
@@ -40,6 +40,19 @@ func (layer *SpriteManager) Add(sprite ISprite) {
 	layer.spriteQueue[n] = sprite
 }
 
+// Clear removes all sprites from the manager, nothing is drawn until new sprites are added and updated
+func (layer *SpriteManager) Clear() {
+	layer.spriteQueue = layer.spriteQueue[:0]
+	layer.spriteProcessed = layer.spriteProcessed[:0]
+
+	layer.mutex.Lock()
+
+	layer.interObjects = 0
+	layer.dirty = true
+
+	layer.mutex.Unlock()
+}
+
 func (layer *SpriteManager) Update(time float64) {
 	dirtyLocal := false
 	toRemove := 0
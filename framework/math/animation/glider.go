@@ -133,6 +133,15 @@ func (glider *Glider) SetValue(value float64) {
 	glider.startValue = value
 }
 
+// Clone returns a copy of the glider with its own queue of pending events
+func (glider *Glider) Clone() *Glider {
+	clone := *glider
+	clone.eventqueue = make([]event, len(glider.eventqueue))
+	copy(clone.eventqueue, glider.eventqueue)
+
+	return &clone
+}
+
 func (glider *Glider) Reset() {
 	glider.eventqueue = make([]event, 0)
 	glider.current.targetValue = glider.value
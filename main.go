@@ -12,25 +12,32 @@ import "C"
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/dustin/go-humanize"
 	"github.com/faiface/mainthread"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/olekukonko/tablewriter"
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/mem"
+	"github.com/wieku/danser-go/app/analysis"
 	"github.com/wieku/danser-go/app/audio"
 	"github.com/wieku/danser-go/app/beatmap"
 	difficulty2 "github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/bmath"
 	camera2 "github.com/wieku/danser-go/app/bmath/camera"
+	"github.com/wieku/danser-go/app/dance"
 	"github.com/wieku/danser-go/app/database"
 	"github.com/wieku/danser-go/app/discord"
 	"github.com/wieku/danser-go/app/ffmpeg"
 	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/oppai"
+	"github.com/wieku/danser-go/app/queue"
 	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
 	"github.com/wieku/danser-go/app/states"
 	"github.com/wieku/danser-go/app/utils"
 	"github.com/wieku/danser-go/build"
@@ -40,6 +47,7 @@ import (
 	batch2 "github.com/wieku/danser-go/framework/graphics/batch"
 	"github.com/wieku/danser-go/framework/graphics/blend"
 	"github.com/wieku/danser-go/framework/graphics/buffer"
+	"github.com/wieku/danser-go/framework/graphics/effects"
 	"github.com/wieku/danser-go/framework/graphics/font"
 	"github.com/wieku/danser-go/framework/graphics/viewport"
 	"github.com/wieku/danser-go/framework/math/vector"
@@ -81,11 +89,13 @@ var win *glfw.Window
 var limiter *frame.Limiter
 var screenFBO *buffer.Framebuffer
 var lastSamples int
+var coverage *effects.CoverageEffect
 var lastVSync bool
 
 var output string
 
 var recordMode bool
+var renderQueue *jobQueue
 var screenshotMode bool
 var screenshotTime float64
 
@@ -111,7 +121,7 @@ func run() {
 		cursors := flag.Int("cursors", 1, "How many repeated cursors should be visible, recommended 2 for mirror, 8 for mandala")
 		tag := flag.Int("tag", 1, "How many cursors should be \"playing\" specific map. 2 means that 1st cursor clicks the 1st object, 2nd clicks 2nd object, 1st clicks 3rd and so on")
 		knockout := flag.Bool("knockout", false, "Use knockout feature")
-		speed := flag.Float64("speed", 1.0, "Specify music's speed, set to 1.5 to have DoubleTime mod experience")
+		speed := flag.Float64("speed", 1.0, "Specify playback rate, e.g. 1.15. DoubleTime and HalfTime are applied on top of it")
 		pitch := flag.Float64("pitch", 1.0, "Specify music's pitch, set to 1.5 with -speed=1.5 to have Nightcore mod experience")
 		debug := flag.Bool("debug", false, "Show info about map and rendering engine, overrides Graphics.ShowFPS setting")
 
@@ -121,6 +131,10 @@ func run() {
 		start := flag.Float64("start", 0, "Start at the given time in seconds")
 		end := flag.Float64("end", math.Inf(1), "End at the given time in seconds")
 
+		practice := flag.Bool("practice", false, "Loop the section given by -start/-end (or set with Input.PracticeStartKey/PracticeEndKey) in -play, rewind it with Input.PracticeRewindKey and slow it down with Input.PracticeSlowerKey/PracticeFasterKey")
+		practiceRate := flag.Float64("practicerate", 1.0, "Initial playback rate of -practice, between 0.5 and 1.0")
+		rewind := flag.Float64("rewind", 5, "How many seconds Input.PracticeRewindKey goes back in -practice")
+
 		skip := flag.Bool("skip", false, "Skip straight to map's drain time")
 
 		quickstart := flag.Bool("quickstart", false, "Sets -skip flag, sets LeadInTime and LeadInHold settings temporarily to 0")
@@ -129,19 +143,52 @@ func run() {
 		out := flag.String("out", "", "If -ss flag is used, sets the name of screenshot, extension is PNG. If not, it overrides -record flag, specifies the name of recorded video file, extension is managed by settings")
 		ss := flag.Float64("ss", math.NaN(), "Screenshot mode. Snap single frame from danser at given time in seconds. Specify the name of file by -out, resolution is managed by Recording settings")
 
-		mods := flag.String("mods", "", "Specify beatmap/play mods. If NC/DT/HT is selected, overrides -speed and -pitch flags")
+		mods := flag.String("mods", "", "Specify beatmap/play mods. If NC/DC is selected, overrides -pitch flag. DA, WU and WD are configured by -ar/-od/-cs/-hp and -initialrate/-finalrate flags")
 
 		replay := flag.String("replay", "", replayDesc)
 		flag.StringVar(replay, "r", "", replayDesc+shorthand)
 
+		analyze := flag.String("analyze", "", "Score the replay given by -replay without opening a window and save the JSON report to the given file")
+
+		hitErrors := flag.String("hiterrors", "", "Export hit errors and unstable rate stats of the replay given by -replay without opening a window. Saved as CSV if file name ends with .csv, JSON otherwise")
+
+		verify := flag.String("verify", "", "Verify replay file or all replays in a directory against scores stored in them, exits with code 1 if any of them doesn't match")
+
+		scoring := flag.String("scoring", "", "Replace Gameplay.ScoringProfile setting temporarily: stable or lazer")
+
 		skin := flag.String("skin", "", "Replace Skin.CurrentSkin setting temporarily")
 
+		saveReplay := flag.Bool("savereplay", false, "Save -play session or cursordance as an osu! replay in replays/exported directory")
+
+		scores := flag.String("scores", "", "List top local scores for the beatmap with given md5 hash and exit")
+
+		ppMode := flag.Bool("pp", false, "Print star rating and pp of a score on the beatmap (using -mods, -acc or -n100/-n50, -misses and -combo) and exit")
+		ppTable := flag.Bool("pptable", false, "Print pp for 95/98/99/100% accuracy with NM/HD/HR/DT on the beatmap and exit")
+		ppAcc := flag.Float64("acc", math.NaN(), "Accuracy in percent for -pp, overrides -n100 and -n50")
+		ppN100 := flag.Int("n100", 0, "Amount of 100s for -pp")
+		ppN50 := flag.Int("n50", 0, "Amount of 50s for -pp")
+		ppMisses := flag.Int("misses", 0, "Amount of misses for -pp")
+		ppCombo := flag.Int("combo", -1, "Max combo for -pp, full combo if not set")
+
+		strains := flag.String("strains", "", "Save per object aim/speed strains of the beatmap (with -mods) to the given file and exit. Saved as CSV if file name ends with .csv, JSON otherwise")
+
 		noDbCheck := flag.Bool("nodbcheck", false, "Don't validate the database and import new beatmaps if there are any. Useful for slow drives.")
 
-		ar := flag.Float64("ar", math.NaN(), "Modify map's AR, only in cursordance/play modes")
-		od := flag.Float64("od", math.NaN(), "Modify map's OD, only in cursordance/play modes")
-		cs := flag.Float64("cs", math.NaN(), "Modify map's CS, only in cursordance/play modes")
-		hp := flag.Float64("hp", math.NaN(), "Modify map's HP, only in cursordance/play modes")
+		ar := flag.Float64("ar", math.NaN(), "Modify map's AR with Difficulty Adjust mod, not available with -replay/-knockout")
+		od := flag.Float64("od", math.NaN(), "Modify map's OD with Difficulty Adjust mod, not available with -replay/-knockout")
+		cs := flag.Float64("cs", math.NaN(), "Modify map's CS with Difficulty Adjust mod, not available with -replay/-knockout")
+		hp := flag.Float64("hp", math.NaN(), "Modify map's HP with Difficulty Adjust mod, not available with -replay/-knockout")
+
+		initialRate := flag.Float64("initialrate", math.NaN(), "Initial playback rate of WU/WD mods, 1.0 by default")
+		finalRate := flag.Float64("finalrate", math.NaN(), "Final playback rate of WU/WD mods, 1.5 for WU and 0.75 for WD by default")
+
+		mirror := flag.String("mirror", "", "Axes reflected by MR mod: h, v or hv. Horizontal by default")
+		seed := flag.Int64("seed", -1, "Seed used by RN mod to place objects, random if not set")
+
+		queueFile := flag.String("queue", "", "Record videos described in the given JSON file one after another. Jobs which output already exists are skipped, so an interrupted queue can be resumed")
+
+		segments := flag.Int("segments", 1, "Split -record into given number of parts recorded by parallel processes and joined without re-encoding. Every part simulates the map from the beginning without drawing it, so frames match a normal recording")
+		segment := flag.String("segment", "", "Used internally by -segments: index/count/name of the part recorded by this process")
 
 		flag.Parse()
 
@@ -152,11 +199,15 @@ func run() {
 			}
 		}
 
-		recordMode = *record
+		recordMode = *record || *queueFile != ""
 		screenshotMode = !math.IsNaN(*ss)
 		screenshotTime = *ss
 
-		if *record && *play {
+		if *queueFile != "" && (*play || screenshotMode || *replay != "" || *out != "" || *saveReplay || *analyze != "" || *hitErrors != "" || *verify != "" || *strains != "" || *ppMode || *ppTable) {
+			panic("Incompatible flags selected: -queue, -play/-ss/-replay/-out/-savereplay/-analyze/-hiterrors/-verify/-strains/-pp/-pptable")
+		} else if *queueFile != "" && (*speed != 1 || !math.IsNaN(*ar) || !math.IsNaN(*od) || !math.IsNaN(*cs) || !math.IsNaN(*hp) || !math.IsNaN(*initialRate) || !math.IsNaN(*finalRate) || *mirror != "" || *seed != -1) {
+			panic("Incompatible flags selected: -queue, -speed/-ar/-od/-cs/-hp/-initialrate/-finalrate/-mirror/-seed")
+		} else if *record && *play {
 			panic("Incompatible flags selected: -record, -play")
 		} else if *replay != "" && *play {
 			panic("Incompatible flags selected: -replay, -play")
@@ -166,10 +217,45 @@ func run() {
 			panic("Incompatible flags selected: -ss, -play")
 		} else if screenshotMode && recordMode {
 			panic("Incompatible flags selected: -ss, -record")
+		} else if *saveReplay && (*replay != "" || *knockout) {
+			panic("Incompatible flags selected: -savereplay, -replay/-knockout")
+		} else if *practice && !*play {
+			panic("-practice requires -play to be specified")
+		} else if *practice && *saveReplay {
+			panic("Incompatible flags selected: -practice, -savereplay")
+		} else if *practice && (*practiceRate < dance.PracticeMinRate || *practiceRate > dance.PracticeMaxRate || *rewind <= 0) {
+			panic("-practicerate has to be between 0.5 and 1.0 and -rewind has to be positive")
+		} else if *analyze != "" && *replay == "" {
+			panic("-analyze requires -replay to be specified")
+		} else if *analyze != "" && (recordMode || screenshotMode) {
+			panic("Incompatible flags selected: -analyze, -record/-ss")
+		} else if *hitErrors != "" && *replay == "" {
+			panic("-hiterrors requires -replay to be specified")
+		} else if *hitErrors != "" && (recordMode || screenshotMode) {
+			panic("Incompatible flags selected: -hiterrors, -record/-ss")
+		} else if *strains != "" && (recordMode || screenshotMode || *play || *replay != "" || *knockout) {
+			panic("Incompatible flags selected: -strains, -record/-ss/-play/-replay/-knockout")
+		} else if (*ppMode || *ppTable) && (recordMode || screenshotMode || *play || *replay != "" || *knockout) {
+			panic("Incompatible flags selected: -pp/-pptable, -record/-ss/-play/-replay/-knockout")
+		} else if *segments < 1 {
+			panic("-segments has to be at least 1")
+		} else if (*segments > 1 || *segment != "") && (!recordMode || *queueFile != "") {
+			panic("-segments requires -record and doesn't work with -queue")
+		}
+
+		if *segment != "" {
+			s, err := ffmpeg.ParseSegment(*segment)
+			if err != nil {
+				panic(err)
+			}
+
+			ffmpeg.SetSegment(s)
 		}
 
 		modsParsed := difficulty2.ParseMods(*mods)
 
+		replayMode := int8(0)
+
 		if *replay != "" {
 			bytes, err := ioutil.ReadFile(*replay)
 			if err != nil {
@@ -181,9 +267,7 @@ func run() {
 				panic(err)
 			}
 
-			if rp.PlayMode != 0 {
-				panic("Modes other than osu!standard are not supported")
-			}
+			replayMode = rp.PlayMode
 
 			*md5 = rp.BeatmapMD5
 			*id = -1
@@ -192,13 +276,84 @@ func run() {
 			settings.REPLAY = *replay
 		}
 
+		modParams := difficulty2.NewModParams()
+		modParams.Rate = *speed
+
+		// Replays and knockout keep mod settings they were played with
+		if *play || !*knockout {
+			modParams.HP, modParams.CS, modParams.OD, modParams.AR = *hp, *cs, *od, *ar
+			modParams.InitialRate, modParams.FinalRate = *initialRate, *finalRate
+
+			switch strings.ToLower(*mirror) {
+			case "", "h":
+				modParams.MirrorHorizontal = true
+			case "v":
+				modParams.MirrorVertical = true
+			case "hv", "vh":
+				modParams.MirrorHorizontal, modParams.MirrorVertical = true, true
+			default:
+				panic("-mirror has to be h, v or hv")
+			}
+
+			modParams.RandomSeed = *seed
+
+			if modParams.IsAdjusted() {
+				modsParsed |= difficulty2.DifficultyAdjust
+			}
+		}
+
 		if !modsParsed.Compatible() {
 			panic("Incompatible mods selected!")
 		}
 
+		if modParams.Rate <= 0 || modParams.InitialRate <= 0 || modParams.FinalRate <= 0 {
+			panic("Playback rates have to be positive")
+		}
+
+		if modParams.IsRamped() && !modsParsed.Active(difficulty2.WindUp|difficulty2.WindDown) {
+			panic("-initialrate/-finalrate require WU or WD mod")
+		}
+
+		if *mirror != "" && !modsParsed.Active(difficulty2.Mirror) {
+			panic("-mirror requires MR mod")
+		}
+
+		if *seed >= 0 && !modsParsed.Active(difficulty2.Random) {
+			panic("-seed requires RN mod")
+		}
+
+		if modsParsed.Active(difficulty2.Random) && modParams.RandomSeed < 0 {
+			modParams.RandomSeed = time.Now().UnixNano() % 1000000
+			log.Println("Random seed:", modParams.RandomSeed)
+		}
+
+		// Segments have to record the same map, they use the database after it's checked by this process
+		segmentArgs := append(append([]string{}, os.Args[1:]...), "-nodbcheck")
+		if modsParsed.Active(difficulty2.Random) && *seed < 0 {
+			segmentArgs = append(segmentArgs, fmt.Sprintf("-seed=%d", modParams.RandomSeed))
+		}
+
+		if *queueFile != "" {
+			jobs, err := queue.Load(*queueFile)
+			if err != nil {
+				panic(err)
+			}
+
+			log.Println(fmt.Sprintf("Loaded %d jobs from %s", len(jobs), *queueFile))
+
+			renderQueue = &jobQueue{
+				path:       *queueFile,
+				jobs:       jobs,
+				settings:   *settingsVersion,
+				skin:       *skin,
+				pitch:      *pitch,
+				quickstart: *quickstart,
+			}
+		}
+
 		closeAfterSettingsLoad := false
 
-		if (*md5+*artist+*title+*difficulty+*creator) == "" && *id < 0 {
+		if (*md5+*artist+*title+*difficulty+*creator) == "" && *id < 0 && *verify == "" && *queueFile == "" {
 			log.Println("No beatmap specified, closing...")
 			closeAfterSettingsLoad = true
 		}
@@ -208,12 +363,15 @@ func run() {
 		settings.PLAY = *play
 		settings.DIVIDES = *cursors
 		settings.TAG = *tag
-		settings.SPEED = *speed
 		settings.PITCH = *pitch
 		settings.SKIP = *skip
 		settings.START = *start
 		settings.END = *end
 		settings.RECORD = recordMode || screenshotMode
+		settings.SAVEREPLAY = *saveReplay
+		settings.PRACTICE = *practice
+		settings.PRACTICERATE = *practiceRate
+		settings.REWIND = *rewind
 
 		if settings.RECORD {
 			bass.Offscreen = true
@@ -226,6 +384,19 @@ func run() {
 			closeAfterSettingsLoad = true
 		}
 
+		if *scoring != "" {
+			if p := strings.ToLower(*scoring); p != "stable" && p != "lazer" {
+				panic("Unknown scoring profile: " + *scoring)
+			}
+
+			settings.Gameplay.ScoringProfile = *scoring
+		}
+
+		if *scores != "" {
+			printTopScores(*scores)
+			os.Exit(0)
+		}
+
 		player = nil
 		var beatMap *beatmap.BeatMap = nil
 
@@ -236,54 +407,55 @@ func run() {
 			} else {
 				beatmaps := database.LoadBeatmaps(*noDbCheck)
 
-				if *id > -1 {
-					for _, b := range beatmaps {
-						if b.ID == *id {
-							beatMap = b
+				if *verify != "" {
+					database.Close()
 
-							break
-						}
-					}
-				} else if *md5 != "" {
-					for _, b := range beatmaps {
-						if strings.EqualFold(b.MD5, *md5) {
-							beatMap = b
+					settings.HEADLESS = true
 
-							break
-						}
-					}
-				} else {
-					for _, b := range beatmaps {
-						if (*artist == "" || strings.EqualFold(*artist, b.Artist)) &&
-							(*title == "" || strings.EqualFold(*title, b.Name)) &&
-							(*difficulty == "" || strings.EqualFold(*difficulty, b.Difficulty)) &&
-							(*creator == "" || strings.EqualFold(*creator, b.Creator)) {
-							beatMap = b
-
-							break
-						}
+					if !analysis.VerifyReplays(*verify, beatmaps) {
+						os.Exit(1)
 					}
 
-					if beatMap == nil {
-						log.Println("Beatmap with exact parameters not found, searching partially...")
-						for _, b := range beatmaps {
-							if (*artist == "" || strings.Contains(strings.ToLower(b.Artist), strings.ToLower(*artist))) &&
-								(*title == "" || strings.Contains(strings.ToLower(b.Name), strings.ToLower(*title))) &&
-								(*difficulty == "" || strings.Contains(strings.ToLower(b.Difficulty), strings.ToLower(*difficulty))) &&
-								(*creator == "" || strings.Contains(strings.ToLower(b.Creator), strings.ToLower(*creator))) {
-								beatMap = b
-
-								break
-							}
-						}
-					}
+					os.Exit(0)
+				}
+
+				if renderQueue != nil {
+					renderQueue.beatmaps = beatmaps
+				} else {
+					beatMap = findBeatmap(beatmaps, *id, *md5, *artist, *title, *difficulty, *creator)
 				}
 			}
 
-			if beatMap == nil {
+			if *verify != "" {
+				os.Exit(1)
+			}
+
+			if beatMap != nil {
+				beatMap.Diff.SetParams(modParams)
+			}
+
+			// osu!standard beatmaps are converted when watching an osu!taiko or osu!catch replay
+			if beatMap != nil && beatMap.Mode == 0 && (replayMode == 1 || replayMode == 2) {
+				beatMap.Mode = int64(replayMode)
+			}
+
+			if beatMap != nil && beatMap.Mode != 0 && (*play || *analyze != "" || *hitErrors != "" || *strains != "" || *ppMode || *ppTable) {
+				panic("-play, -analyze, -hiterrors, -strains, -pp and -pptable support only osu!standard beatmaps")
+			}
+
+			if beatMap != nil && beatMap.Mode != 0 && *knockout && *replay == "" {
+				panic("-knockout supports only osu!standard beatmaps")
+			}
+
+			if renderQueue != nil {
+				if renderQueue.beatmaps == nil {
+					log.Println("Beatmaps couldn't be loaded, closing...")
+					closeAfterSettingsLoad = true
+				}
+			} else if beatMap == nil {
 				log.Println("Beatmap not found, closing...")
 				closeAfterSettingsLoad = true
-			} else {
+			} else if !*ppMode && !*ppTable && *strains == "" && *analyze == "" && *hitErrors == "" && !ffmpeg.IsSegment() {
 				beatMap.UpdatePlayStats()
 				database.UpdatePlayStats(beatMap)
 			}
@@ -291,6 +463,78 @@ func run() {
 			database.Close()
 		}
 
+		if *strains != "" {
+			if beatMap == nil {
+				os.Exit(1)
+			}
+
+			settings.HEADLESS = true
+
+			beatMap.Diff.SetMods(modsParsed)
+			beatmap.ParseTimingPointsAndPauses(beatMap)
+			beatmap.ParseObjects(beatMap)
+
+			if err := analysis.CalculateStrains(beatMap, modsParsed).Save(*strains); err != nil {
+				panic(err)
+			}
+
+			log.Println("Strains saved to:", *strains)
+
+			os.Exit(0)
+		}
+
+		if *ppMode || *ppTable {
+			if beatMap == nil {
+				os.Exit(1)
+			}
+
+			settings.HEADLESS = true
+
+			beatMap.Diff.SetMods(modsParsed)
+			beatmap.ParseTimingPointsAndPauses(beatMap)
+			beatmap.ParseObjects(beatMap)
+
+			if *ppTable {
+				printPPTable(beatMap)
+			} else {
+				printPP(beatMap, modsParsed, *ppAcc, *ppN100, *ppN50, *ppMisses, *ppCombo)
+			}
+
+			os.Exit(0)
+		}
+
+		if *analyze != "" || *hitErrors != "" {
+			if beatMap == nil {
+				os.Exit(1)
+			}
+
+			settings.HEADLESS = true
+
+			beatMap.Diff.SetMods(modsParsed)
+			beatmap.ParseTimingPointsAndPauses(beatMap)
+			beatmap.ParseObjects(beatMap)
+
+			report := analysis.AnalyzeReplay(beatMap)
+
+			if *analyze != "" {
+				if err := report.Save(*analyze); err != nil {
+					panic(err)
+				}
+
+				log.Println("Report saved to:", *analyze)
+			}
+
+			if *hitErrors != "" {
+				if err := report.Timings.Save(*hitErrors); err != nil {
+					panic(err)
+				}
+
+				log.Println("Hit errors saved to:", *hitErrors)
+			}
+
+			os.Exit(0)
+		}
+
 		assets.Init(build.Stream == "Dev")
 
 		if !closeAfterSettingsLoad {
@@ -335,18 +579,30 @@ func run() {
 		}
 
 		if settings.RECORD {
-			//HACK: some in-app variables depend on these settings so we force them here
-			settings.Graphics.VSync = false
-			settings.Graphics.ShowFPS = false
-			settings.DEBUG = false
-			settings.Graphics.Fullscreen = false
-			settings.Graphics.WindowWidth = int64(settings.Recording.FrameWidth)
-			settings.Graphics.WindowHeight = int64(settings.Recording.FrameHeight)
-			settings.Playfield.LeadInTime = 0
+			applyRecordSettings()
 		} else {
 			discord.Connect()
 		}
 
+		if *segments > 1 && !ffmpeg.IsSegment() {
+			executable, err := os.Executable()
+			if err != nil {
+				panic(err)
+			}
+
+			if err = ffmpeg.RecordSegments(*segments, executable, segmentArgs, output); err != nil {
+				log.Println("Segmented recording failed:", err)
+				os.Exit(1)
+			}
+
+			os.Exit(0)
+		}
+
+		if ffmpeg.IsSegment() {
+			// Segments run in parallel, they can't share the address
+			settings.Recording.StatusAddress = ""
+		}
+
 		if screenshotMode {
 			settings.Playfield.LeadInHold = 0
 			settings.START = screenshotTime - 5
@@ -375,7 +631,10 @@ func run() {
 			})
 		}
 
-		win.SetTitle("danser " + build.VERSION + " - " + beatMap.Artist + " - " + beatMap.Name + " [" + beatMap.Difficulty + "]")
+		if beatMap != nil {
+			win.SetTitle("danser " + build.VERSION + " - " + beatMap.Artist + " - " + beatMap.Name + " [" + beatMap.Difficulty + "]")
+		}
+
 		input.Win = win
 
 		icon, eee := assets.GetPixmap("assets/textures/dansercoin.png")
@@ -476,40 +735,17 @@ func run() {
 		lastVSync = true
 
 		bass.Init(settings.RECORD)
-		audio.LoadSamples()
 
-		speedBefore := settings.SPEED
+		if renderQueue != nil {
+			return
+		}
+
+		audio.LoadSamples()
 
 		if modsParsed.Active(difficulty2.Nightcore) {
-			settings.SPEED *= 1.5
 			settings.PITCH *= 1.5
-		} else if modsParsed.Active(difficulty2.DoubleTime) {
-			settings.SPEED *= 1.5
 		} else if modsParsed.Active(difficulty2.Daycore) {
 			settings.PITCH *= 0.75
-			settings.SPEED *= 0.75
-		} else if modsParsed.Active(difficulty2.HalfTime) {
-			settings.SPEED *= 0.75
-		}
-
-		if settings.PLAY || !settings.KNOCKOUT {
-			if !math.IsNaN(*ar) {
-				beatMap.Diff.SetAR(*ar)
-			}
-
-			if !math.IsNaN(*od) {
-				beatMap.Diff.SetOD(*od)
-			}
-
-			if !math.IsNaN(*cs) {
-				beatMap.Diff.SetCS(*cs)
-			}
-
-			if !math.IsNaN(*hp) {
-				beatMap.Diff.SetHPDrain(*hp)
-			}
-
-			beatMap.Diff.SetCustomSpeed(speedBefore)
 		}
 
 		beatMap.Diff.SetMods(modsParsed)
@@ -521,8 +757,14 @@ func run() {
 		limiter = frame.NewLimiter(int(settings.Graphics.FPSCap))
 	})
 
-	if recordMode {
-		mainLoopRecord()
+	if renderQueue != nil {
+		mainLoopQueue()
+	} else if recordMode {
+		// Exit code tells -segments whether its parts were recorded
+		if err := mainLoopRecord(); err != nil && err != ffmpeg.ErrCancelled {
+			log.Println("Recording failed:", err)
+			os.Exit(1)
+		}
 	} else if screenshotMode {
 		mainLoopSS()
 	} else {
@@ -530,7 +772,7 @@ func run() {
 	}
 }
 
-func mainLoopRecord() {
+func mainLoopRecord() error {
 	count := 0
 
 	fps := float64(settings.Recording.FPS)
@@ -547,7 +789,13 @@ func mainLoopRecord() {
 		fbo = buffer.NewFrameMultisampleScreen(w, h, false, 0)
 	})
 
-	ffmpeg.StartFFmpeg(int(fps), w, h)
+	p, _ := player.(*states.Player)
+
+	ffmpeg.SetMetadata(p.GetMetadata())
+
+	ffmpeg.StartStatusServer()
+	ffmpeg.StartFFmpeg(int(fps), w, h, output)
+	ffmpeg.SetVideoLength(p.GetVideoTime(p.MapEnd))
 
 	updateFPS := math.Max(fps, 1000)
 	updateDelta := 1000 / updateFPS
@@ -555,15 +803,27 @@ func mainLoopRecord() {
 
 	deltaSumF := fpsDelta
 
-	p, _ := player.(*states.Player)
-
 	//maxFrames := int(p.RunningTime / settings.SPEED / 1000 * fps)
 
 	var lastProgress, progress int
 
 	for !p.Update(updateDelta) {
+		if ffmpeg.IsCancelled() {
+			log.Println("Recording cancelled, saving what was recorded so far...")
+			break
+		}
+
+		if ffmpeg.IsSegmentFinished() {
+			break
+		}
+
 		deltaSumF += updateDelta
-		if deltaSumF >= fpsDelta {
+		if deltaSumF >= fpsDelta && ffmpeg.IsFrameSkipped() {
+			// Segment's lead-in is only simulated, like with -start
+			ffmpeg.SkipFrame()
+
+			deltaSumF -= fpsDelta
+		} else if deltaSumF >= fpsDelta {
 			mainthread.Call(func() {
 				fbo.Bind()
 
@@ -579,6 +839,8 @@ func mainLoopRecord() {
 
 				count++
 
+				ffmpeg.UpdateProgress(p.GetTime(), p.MapEnd, p.GetTimeOffset()/p.RunningTime)
+
 				progress = int(math.Round(p.GetTimeOffset() / p.RunningTime /*float64(count) / float64(maxFrames)*/ * 100))
 
 				if progress%5 == 0 && lastProgress != progress {
@@ -596,13 +858,319 @@ func mainLoopRecord() {
 		}
 	}
 
+	if !ffmpeg.IsCancelled() && !ffmpeg.IsSegmentFinished() {
+		p.SaveResults()
+	}
+
+	var err error
+
 	mainthread.Call(func() {
-		ffmpeg.StopFFmpeg()
+		err = ffmpeg.StopFFmpeg()
+		fbo.Dispose()
 	})
 
-	bass.SaveToFile(filepath.Join(settings.Recording.OutputDir, ffmpeg.GetFileName()+".wav"))
+	if ffmpeg.IsSegment() {
+		err = ffmpeg.FinishSegment(err)
+	} else if !ffmpeg.IsSinglePass() {
+		bass.SaveToFile(filepath.Join(settings.Recording.OutputDir, ffmpeg.GetFileName()+".wav"))
+
+		err = ffmpeg.Combine()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if ffmpeg.IsCancelled() {
+		return ffmpeg.ErrCancelled
+	}
+
+	return nil
+}
 
-	ffmpeg.Combine(output)
+// jobQueue holds -queue jobs and command line options applied to each of them
+type jobQueue struct {
+	path     string
+	jobs     []*queue.Job
+	beatmaps []*beatmap.BeatMap
+
+	settings   string
+	skin       string
+	pitch      float64
+	quickstart bool
+}
+
+func mainLoopQueue() {
+	report := queue.NewReport(renderQueue.path, renderQueue.jobs)
+
+	saveReport := func(index int, status queue.Status, err error, duration float64) {
+		if err := report.Set(index, status, err, duration); err != nil {
+			log.Println("Failed to save queue report:", err)
+		}
+	}
+
+	for i, job := range renderQueue.jobs {
+		log.Println(fmt.Sprintf("Queue: job %d/%d: %s", i+1, len(renderQueue.jobs), job.Out))
+
+		saveReport(i, queue.Running, nil, 0)
+
+		startTime := time.Now()
+
+		skipped, err := recordJob(job)
+
+		duration := time.Since(startTime).Seconds()
+
+		if err == ffmpeg.ErrCancelled {
+			log.Println("CANCELLED:", job.Out, "- remaining jobs won't be recorded")
+			saveReport(i, queue.Failed, err, duration)
+
+			break
+		} else if err != nil {
+			log.Println("FAIL:", job.Out, "-", err)
+			saveReport(i, queue.Failed, err, duration)
+		} else if skipped {
+			log.Println("SKIP:", job.Out, "- output already exists")
+			saveReport(i, queue.Skipped, nil, duration)
+		} else {
+			log.Println("OK:", job.Out)
+			saveReport(i, queue.Done, nil, duration)
+		}
+	}
+
+	done, skipped, failed := report.Count(queue.Done), report.Count(queue.Skipped), report.Count(queue.Failed)
+
+	log.Println(fmt.Sprintf("Queue finished: %d done, %d skipped, %d failed. Report saved to: %s", done, skipped, failed, report.GetPath()))
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// recordJob records a single -queue job. Errors found while loading the job are returned instead of closing danser,
+// crash during recording leaves the job marked as running in the report.
+func recordJob(job *queue.Job) (skipped bool, err error) {
+	err = mainthread.CallErr(func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				for _, s := range utils.GetPanicStackTrace() {
+					log.Println(s)
+				}
+
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		skipped, err = loadJob(job)
+
+		return
+	})
+
+	if err != nil || skipped {
+		return
+	}
+
+	err = mainLoopRecord()
+
+	player = nil
+
+	return
+}
+
+// loadJob applies job's settings and creates the player, it has to be called from the main thread
+func loadJob(job *queue.Job) (bool, error) {
+	settingsVersion := renderQueue.settings
+
+	if job.Settings != "" {
+		if _, err := os.Stat("settings-" + job.Settings + ".json"); err != nil {
+			return false, fmt.Errorf("settings version %q doesn't exist", job.Settings)
+		}
+
+		settingsVersion = job.Settings
+	}
+
+	// Reloading settings also reverts overrides made for the previous job
+	settings.LoadSettings(settingsVersion)
+
+	if strings.TrimSpace(job.Skin) != "" {
+		settings.Skin.CurrentSkin = job.Skin
+	} else if strings.TrimSpace(renderQueue.skin) != "" {
+		settings.Skin.CurrentSkin = renderQueue.skin
+	}
+
+	settings.KNOCKOUT = job.Knockout
+	settings.REPLAY = ""
+	settings.PITCH = renderQueue.pitch
+	settings.SKIP = job.Skip || renderQueue.quickstart
+	settings.START = job.Start
+	settings.END = job.GetEnd()
+
+	if renderQueue.quickstart {
+		settings.Playfield.LeadInTime = 0
+		settings.Playfield.LeadInHold = 0
+	}
+
+	applyRecordSettings()
+
+	if _, err := os.Stat(ffmpeg.GetOutputPath(job.Out)); err == nil {
+		return true, nil
+	}
+
+	if !job.HasBeatmap() {
+		return false, errors.New("no beatmap specified")
+	}
+
+	mods := difficulty2.ParseMods(job.Mods)
+
+	id, md5 := job.ID, job.MD5
+	if id <= 0 {
+		id = -1
+	}
+
+	replayMode := int8(0)
+
+	if job.Replay != "" {
+		bytes, err := ioutil.ReadFile(job.Replay)
+		if err != nil {
+			return false, err
+		}
+
+		rp, err := rplpa.ParseReplay(bytes)
+		if err != nil {
+			return false, err
+		}
+
+		replayMode = rp.PlayMode
+
+		id, md5 = -1, rp.BeatmapMD5
+		mods = difficulty2.Modifier(rp.Mods)
+		settings.KNOCKOUT = true
+		settings.REPLAY = job.Replay
+	}
+
+	if !mods.Compatible() {
+		return false, errors.New("incompatible mods selected")
+	}
+
+	found := findBeatmap(renderQueue.beatmaps, id, md5, job.Artist, job.Title, job.Difficulty, job.Creator)
+	if found == nil {
+		return false, errors.New("beatmap not found")
+	}
+
+	beatMap := found.Copy()
+
+	if beatMap.Mode == 0 && (replayMode == 1 || replayMode == 2) {
+		beatMap.Mode = int64(replayMode)
+	}
+
+	if beatMap.Mode != 0 && job.Knockout && job.Replay == "" {
+		return false, errors.New("knockout supports only osu!standard beatmaps")
+	}
+
+	modParams := difficulty2.NewModParams()
+
+	if mods.Active(difficulty2.Random) {
+		modParams.RandomSeed = time.Now().UnixNano() % 1000000
+		log.Println("Random seed:", modParams.RandomSeed)
+	}
+
+	beatMap.Diff.SetParams(modParams)
+
+	if mods.Active(difficulty2.Nightcore) {
+		settings.PITCH *= 1.5
+	} else if mods.Active(difficulty2.Daycore) {
+		settings.PITCH *= 0.75
+	}
+
+	// Skin atlas is kept between jobs unless they use different skins
+	if skin.CurrentSkin != settings.Skin.CurrentSkin {
+		skin.Unload()
+	}
+
+	audio.LoadSamples()
+
+	win.SetSize(int(settings.Graphics.WindowWidth), int(settings.Graphics.WindowHeight))
+
+	beatMap.Diff.SetMods(mods)
+	beatmap.ParseTimingPointsAndPauses(beatMap)
+	beatmap.ParseObjects(beatMap)
+	beatMap.LoadCustomSamples()
+
+	output = job.Out
+	player = states.NewPlayer(beatMap)
+
+	return false, nil
+}
+
+func findBeatmap(beatmaps []*beatmap.BeatMap, id int64, md5, artist, title, difficulty, creator string) *beatmap.BeatMap {
+	if id > -1 {
+		for _, b := range beatmaps {
+			if b.ID == id {
+				return b
+			}
+		}
+
+		return nil
+	}
+
+	if md5 != "" {
+		for _, b := range beatmaps {
+			if strings.EqualFold(b.MD5, md5) {
+				return b
+			}
+		}
+
+		return nil
+	}
+
+	// Other modes are picked only if there's no osu!standard difficulty matching the search
+	search := func(matches func(b *beatmap.BeatMap) bool) (found *beatmap.BeatMap) {
+		for _, b := range beatmaps {
+			if !matches(b) {
+				continue
+			}
+
+			if b.Mode == 0 {
+				return b
+			}
+
+			if found == nil {
+				found = b
+			}
+		}
+
+		return
+	}
+
+	found := search(func(b *beatmap.BeatMap) bool {
+		return (artist == "" || strings.EqualFold(artist, b.Artist)) &&
+			(title == "" || strings.EqualFold(title, b.Name)) &&
+			(difficulty == "" || strings.EqualFold(difficulty, b.Difficulty)) &&
+			(creator == "" || strings.EqualFold(creator, b.Creator))
+	})
+
+	if found != nil {
+		return found
+	}
+
+	log.Println("Beatmap with exact parameters not found, searching partially...")
+
+	return search(func(b *beatmap.BeatMap) bool {
+		return (artist == "" || strings.Contains(strings.ToLower(b.Artist), strings.ToLower(artist))) &&
+			(title == "" || strings.Contains(strings.ToLower(b.Name), strings.ToLower(title))) &&
+			(difficulty == "" || strings.Contains(strings.ToLower(b.Difficulty), strings.ToLower(difficulty))) &&
+			(creator == "" || strings.Contains(strings.ToLower(b.Creator), strings.ToLower(creator)))
+	})
+}
+
+// HACK: some in-app variables depend on these settings so we force them when recording
+func applyRecordSettings() {
+	settings.Graphics.VSync = false
+	settings.Graphics.ShowFPS = false
+	settings.DEBUG = false
+	settings.Graphics.Fullscreen = false
+	settings.Graphics.WindowWidth = int64(settings.Recording.FrameWidth)
+	settings.Graphics.WindowHeight = int64(settings.Recording.FrameHeight)
+	settings.Playfield.LeadInTime = 0
 }
 
 func mainLoopSS() {
@@ -687,9 +1255,138 @@ func mainLoopNormal() {
 		})
 	}
 
+	if p, ok := player.(*states.Player); ok {
+		p.SaveResults()
+	}
+
 	settings.CloseWatcher()
 }
 
+func printTopScores(md5 string) {
+	err := database.Init()
+	if err != nil {
+		log.Println("Failed to initialize database:", err)
+		return
+	}
+
+	defer database.Close()
+
+	scores, err := database.GetTopScores(md5, 50)
+	if err != nil {
+		log.Println("Failed to load scores:", err)
+		return
+	}
+
+	if len(scores) == 0 {
+		log.Println("No local scores found for:", md5)
+		return
+	}
+
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader([]string{"#", "Player", "Score", "Accuracy", "Grade", "300", "100", "50", "Miss", "Max Combo", "Mods", "PP", "Date"})
+
+	for i, s := range scores {
+		table.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			s.Name,
+			utils.Humanize(s.Score),
+			fmt.Sprintf("%.2f", s.Accuracy),
+			s.Grade,
+			utils.Humanize(s.Count300),
+			utils.Humanize(s.Count100),
+			utils.Humanize(s.Count50),
+			utils.Humanize(s.CountMiss),
+			utils.Humanize(s.MaxCombo),
+			s.Mods,
+			fmt.Sprintf("%.2f", s.PP),
+			s.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	table.Render()
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+}
+
+func newModdedDifficulty(beatMap *beatmap.BeatMap, mods difficulty2.Modifier) *difficulty2.Difficulty {
+	diff := beatMap.Diff.Clone()
+	diff.SetMods(mods)
+
+	return diff
+}
+
+func printPP(beatMap *beatmap.BeatMap, mods difficulty2.Modifier, acc float64, n100, n50, misses, combo int) {
+	if !math.IsNaN(acc) {
+		n100, n50 = oppai.HitsFromAccuracy(len(beatMap.HitObjects), misses, acc)
+	}
+
+	result := oppai.CalculatePP(beatMap.HitObjects, newModdedDifficulty(beatMap, mods), combo, n100, n50, misses)
+
+	modString := mods.String()
+	if modString == "" {
+		modString = "NM"
+	}
+
+	log.Println(fmt.Sprintf("%s - %s [%s] +%s", beatMap.Artist, beatMap.Name, beatMap.Difficulty, modString))
+
+	if params := beatMap.Diff.GetParamsString(); params != "" {
+		log.Println("Mod settings:", params)
+	}
+
+	log.Println(fmt.Sprintf("Stars: %.2f (aim: %.2f, speed: %.2f)", result.Stars.Total, result.Stars.Aim, result.Stars.Speed))
+	log.Println(fmt.Sprintf("Score: %.2f%%, %dx/%dx, %dx300 %dx100 %dx50 %dxMiss", result.Accuracy, result.Combo, result.Stats.MaxCombo, result.N300, result.N100, result.N50, result.NMiss))
+	log.Println(fmt.Sprintf("PP: %.2f (aim: %.2f, speed: %.2f, acc: %.2f)", result.PP.Total, result.PP.Aim, result.PP.Speed, result.PP.Acc))
+}
+
+func printPPTable(beatMap *beatmap.BeatMap) {
+	accuracies := []float64{95, 98, 99, 100}
+	modSets := []difficulty2.Modifier{difficulty2.None, difficulty2.Hidden, difficulty2.HardRock, difficulty2.DoubleTime}
+
+	stats := oppai.GetMapStats(beatMap.HitObjects)
+
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+
+	header := []string{"Mods", "Stars"}
+	for _, acc := range accuracies {
+		header = append(header, fmt.Sprintf("%.0f%%", acc))
+	}
+
+	table.SetHeader(header)
+
+	for _, mods := range modSets {
+		diff := newModdedDifficulty(beatMap, mods)
+		stars := oppai.CalculateSingle(beatMap.HitObjects, diff, false)
+
+		modString := mods.String()
+		if modString == "" {
+			modString = "NM"
+		}
+
+		row := []string{modString, fmt.Sprintf("%.2f", stars.Total)}
+
+		for _, acc := range accuracies {
+			n100, n50 := oppai.HitsFromAccuracy(stats.NObjects, 0, acc)
+			result := oppai.CalculatePPWithStars(stars, stats, diff, -1, n100, n50, 0)
+
+			row = append(row, fmt.Sprintf("%.2f", result.PP.Total))
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+
+	log.Println(fmt.Sprintf("%s - %s [%s]", beatMap.Artist, beatMap.Name, beatMap.Difficulty))
+
+	for _, s := range strings.Split(tableString.String(), "\n") {
+		log.Println(s)
+	}
+}
+
 func extensionCheck() {
 	extensions := []string{
 		"GL_ARB_clear_texture",
@@ -741,11 +1438,30 @@ func pushFrame() {
 		lastSamples = int(settings.Graphics.MSAA)
 	}
 
+	transparent := settings.Recording.IsTransparent()
+
+	if transparent {
+		if coverage == nil || coverage.GetWidth() != screenFBO.GetWidth() || coverage.GetHeight() != screenFBO.GetHeight() {
+			if coverage != nil {
+				coverage.Dispose()
+			}
+
+			coverage = effects.NewCoverageEffect(screenFBO.GetWidth(), screenFBO.GetHeight())
+		}
+
+		coverage.Begin()
+	}
+
 	if lastSamples > 0 {
 		screenFBO.Bind()
 	}
 
-	gl.ClearColor(0, 0, 0, 1)
+	if transparent {
+		gl.ClearColor(0, 0, 0, 0)
+	} else {
+		gl.ClearColor(0, 0, 0, 1)
+	}
+
 	gl.Clear(gl.COLOR_BUFFER_BIT)
 
 	if player != nil {
@@ -756,6 +1472,10 @@ func pushFrame() {
 		screenFBO.Unbind()
 	}
 
+	if transparent {
+		coverage.EndAndRender()
+	}
+
 	blend.ClearStack()
 	viewport.Pop()
 }